@@ -0,0 +1,80 @@
+package guillotine
+
+import "math/rand"
+
+//Adjusts mutation after every generation, given the fraction of
+//children that turned out fitter than their parents.
+type MutationAdapter interface {
+	Adapt(generation uint, successRate float64)
+}
+
+//Mutation means at a given generation.
+type MutationStep struct {
+	Generation  uint
+	SuccessRate float64
+	WeightMean  float64
+	ConfigMean  float64
+}
+
+//Weight and config mutation whose intensity follows the 1/5th success
+//rule: when more than a fifth of the children improve on their
+//parents the means grow, to explore further; otherwise they shrink, to
+//refine the current solutions. Standard deviations scale along with
+//the means. It must be set both as the GeneticAlgorithm Mutator and
+//as its Adapter.
+type AdaptiveMutator struct {
+	Weight NormalWeightMutator
+	Config NormalConfigMutator
+	//Shrink factor, in (0, 1). Means grow by 1/Factor.
+	Factor float64
+	//Bounds for both means
+	MinMean, MaxMean float64
+	//Means after every adaptation
+	Trajectory []MutationStep
+}
+
+func NewAdaptiveMutator(weightMean, configMean float64) *AdaptiveMutator {
+	return &AdaptiveMutator{
+		Weight:  NormalWeightMutator{Mean: weightMean, StdDev: weightMean / 5},
+		Config:  NormalConfigMutator{Mean: configMean, StdDev: configMean / 5},
+		Factor:  0.85,
+		MinMean: 0.5,
+		MaxMean: 100,
+	}
+}
+
+func (am *AdaptiveMutator) Mutate(g Genotype, r *rand.Rand) {
+	am.Weight.Mutate(g, r)
+	am.Config.Mutate(g, r)
+}
+
+func (am *AdaptiveMutator) Adapt(generation uint, successRate float64) {
+	factor := am.Factor
+	if successRate > 0.2 {
+		factor = 1 / factor
+	}
+	am.Weight.Mean, am.Weight.StdDev = am.scale(am.Weight.Mean, am.Weight.StdDev, factor)
+	am.Config.Mean, am.Config.StdDev = am.scale(am.Config.Mean, am.Config.StdDev, factor)
+	am.Trajectory = append(am.Trajectory, MutationStep{
+		Generation:  generation,
+		SuccessRate: successRate,
+		WeightMean:  am.Weight.Mean,
+		ConfigMean:  am.Config.Mean,
+	})
+}
+
+func (am *AdaptiveMutator) scale(mean, stddev, factor float64) (float64, float64) {
+	scaled := mean * factor
+	if scaled < am.MinMean {
+		scaled = am.MinMean
+	} else if scaled > am.MaxMean {
+		scaled = am.MaxMean
+	}
+	if mean == 0 {
+		return scaled, stddev
+	}
+	return scaled, stddev * scaled / mean
+}
+
+var _ Mutator = (&AdaptiveMutator{}).Mutate
+var _ MutationAdapter = &AdaptiveMutator{}
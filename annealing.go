@@ -0,0 +1,245 @@
+package guillotine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+//Temperature on the given step of a cooling schedule of steps steps,
+//starting from t0.
+type Cooling func(t0 float64, step, steps uint) float64
+
+//Multiplies the temperature by rate, in (0, 1), on every step.
+func GeometricCooling(rate float64) Cooling {
+	return func(t0 float64, step, steps uint) float64 {
+		return t0 * math.Pow(rate, float64(step))
+	}
+}
+
+//Lowers the temperature by the same amount on every step, reaching 0
+//right after the last one.
+func LinearCooling(t0 float64, step, steps uint) float64 {
+	return t0 * (1 - float64(step)/float64(steps))
+}
+
+//Slowly lowers the temperature as t0 / (1 + ln(1 + step)).
+func LogarithmicCooling(t0 float64, step, steps uint) float64 {
+	return t0 / (1 + math.Log(1+float64(step)))
+}
+
+//Cooling schedule by name: geometric, linear or logarithmic. Only the
+//geometric schedule uses rate.
+func ParseCooling(name string, rate float64) (Cooling, error) {
+	switch name {
+	case "geometric":
+		if rate <= 0 || rate >= 1 {
+			return nil, fmt.Errorf("geometric cooling rate must be in (0, 1), got %v", rate)
+		}
+		return GeometricCooling(rate), nil
+	case "linear":
+		return LinearCooling, nil
+	case "logarithmic":
+		return LogarithmicCooling, nil
+	}
+	return nil, fmt.Errorf("unknown cooling schedule %q", name)
+}
+
+//Neighbours sampled to pick the initial temperature.
+const temperatureSamples = 20
+
+//Simulated annealing over a single genotype, an alternative to the
+//GeneticAlgorithm for small and medium specs. Neighbours are made by
+//mutating a copy of the current genotype, and replace it when they're
+//no worse, or otherwise with probability exp(-delta/T), delta being
+//how much worse they are. The temperature T is lowered by Cooling
+//after every Moves neighbours, a level, which is what Progress
+//reports as a generation.
+type SimulatedAnnealing struct {
+	Spec      *CutSpec
+	Evaluator Fitness
+	//Neighbourhood move, usually small, see CompoundMutator
+	Mutator Mutator
+	Cooling Cooling
+	//Initial temperature in fitness units, 0 picks one that accepts
+	//most of the worse neighbours of the first genotype.
+	Temperature float64
+	//Neighbours evaluated on each level, must be positive
+	Moves uint
+	//Levels of each cooling schedule
+	Levels uint
+	//Times the schedule starts over, reheated, from the best genotype
+	//found so far.
+	Restarts uint
+	R        *rand.Rand
+	//Optional, the Source behind R
+	Source *Source
+	//Optional, the first genotype instead of a random one
+	Start Genotype
+	//Optional, notified of the progress of every level. The population
+	//only holds the current genotype.
+	Observer Observer
+	//When to end a run, defaults to MaxGenerations(Levels*(Restarts+1))
+	Stop StopCondition
+	//Optional, skips evaluating genotypes seen before
+	Cache *FitnessCache
+}
+
+//State of an annealing run.
+type annealing struct {
+	current     Genotype
+	fitness     uint
+	best        Genotype
+	bestFitness uint
+	evaluations uint
+}
+
+func (sa *SimulatedAnnealing) evaluate(st *annealing, g Genotype) uint {
+	st.evaluations++
+	if sa.Cache != nil {
+		return sa.Cache.fitness(sa.Spec, sa.Evaluator, g)
+	}
+	return sa.Evaluator(GetPhenotype(sa.Spec, g))
+}
+
+func (sa *SimulatedAnnealing) neighbour(st *annealing) (Genotype, uint) {
+	g := st.current.copy()
+	sa.Mutator(g, sa.R)
+	return g, sa.evaluate(st, g)
+}
+
+//A temperature accepting 80% of the average worse neighbour of the
+//current genotype, or 1 when there are no worse neighbours.
+func (sa *SimulatedAnnealing) initialTemperature(st *annealing) float64 {
+	var sum float64
+	var worse int
+	for i := 0; i < temperatureSamples; i++ {
+		if _, f := sa.neighbour(st); f > st.fitness {
+			sum += float64(f - st.fitness)
+			worse++
+		}
+	}
+	if worse == 0 {
+		return 1
+	}
+	return sum / float64(worse) / -math.Log(0.8)
+}
+
+//Tries Moves neighbours at the given temperature, returning the mean
+//fitness of the current genotype along them.
+func (sa *SimulatedAnnealing) level(st *annealing, temperature float64) float64 {
+	var sum float64
+	for i := uint(0); i < sa.Moves; i++ {
+		g, f := sa.neighbour(st)
+		if f <= st.fitness || temperature > 0 &&
+			sa.R.Float64() < math.Exp(-float64(f-st.fitness)/temperature) {
+			st.current, st.fitness = g, f
+			if f < st.bestFitness {
+				st.best, st.bestFitness = g, f
+			}
+		}
+		sum += float64(st.fitness)
+	}
+	return sum / float64(sa.Moves)
+}
+
+//Progress after a level, prev is the progress of the previous one,
+//nil for the first.
+func (sa *SimulatedAnnealing) progress(prev *Progress, st *annealing, mean float64, start time.Time) *Progress {
+	p := &Progress{
+		Generation:  1,
+		Best:        st.fitness,
+		Mean:        mean,
+		Phenotype:   GetPhenotype(sa.Spec, st.current),
+		Population:  &RankedPopulation{Pop: Population{st.current}, Fitnesses: []uint{st.fitness}},
+		Elapsed:     time.Since(start),
+		Evaluations: st.evaluations,
+	}
+	if prev != nil {
+		p.Generation += prev.Generation
+		p.BestSoFar, p.BestPhenotype, p.Improved = prev.BestSoFar, prev.BestPhenotype, prev.Improved
+	}
+	if p.BestPhenotype == nil || st.bestFitness < p.BestSoFar {
+		p.BestSoFar, p.BestPhenotype, p.Improved = st.bestFitness, GetPhenotype(sa.Spec, st.best), p.Generation
+	}
+	if sa.Observer != nil {
+		sa.Observer(p)
+	}
+	return p
+}
+
+func (sa *SimulatedAnnealing) stopCondition() StopCondition {
+	if sa.Stop != nil {
+		return sa.Stop
+	}
+	return MaxGenerations(sa.Levels * (sa.Restarts + 1))
+}
+
+//Anneals until stop says so, or until ctx is done. On cancellation,
+//the result holds the best layout found so far along with the
+//context's error.
+func (sa *SimulatedAnnealing) run(ctx context.Context, stop StopCondition) (*RunResult, error) {
+	start := time.Now()
+	st := &annealing{current: sa.Start}
+	if st.current == nil {
+		st.current = NewRandomGenotype(uint16(len(sa.Spec.Boards)), sa.R)
+	}
+	st.fitness = sa.evaluate(st, st.current)
+	st.best, st.bestFitness = st.current, st.fitness
+	t0 := sa.Temperature
+	if t0 == 0 {
+		t0 = sa.initialTemperature(st)
+	}
+	levels := sa.Levels
+	if levels == 0 {
+		levels = 1
+	}
+	var p *Progress
+	var err error
+	var reason string
+	for {
+		step := uint(0)
+		if p != nil {
+			var done bool
+			if done, reason = stop(p); done {
+				break
+			}
+			if err = ctx.Err(); err != nil {
+				reason = err.Error()
+				break
+			}
+			step = p.Generation % levels
+		}
+		if p != nil && step == 0 {
+			st.current, st.fitness = st.best, st.bestFitness
+		}
+		mean := sa.level(st, sa.Cooling(t0, step, levels))
+		p = sa.progress(p, st, mean, start)
+	}
+	result := &RunResult{
+		Generations: p.Generation,
+		Evaluations: p.Evaluations,
+		Layout:      p.BestPhenotype,
+		Fitness:     p.BestSoFar,
+		Population:  p.Population,
+		Reason:      reason,
+	}
+	if sa.Source != nil {
+		result.Seed, _ = sa.Source.State()
+	}
+	return result, err
+}
+
+//Runs until sa.Stop, or all the levels of every restart if there's no
+//Stop condition.
+func (sa *SimulatedAnnealing) RunContext(ctx context.Context) (*RunResult, error) {
+	return sa.run(ctx, sa.stopCondition())
+}
+
+//Like RunContext, but also stops when the next level is expected to
+//end past the time limit.
+func (sa *SimulatedAnnealing) TimeBoundedRunContext(ctx context.Context, limit time.Duration) (*RunResult, error) {
+	return sa.run(ctx, AnyOf(sa.stopCondition(), WallClock(limit)))
+}
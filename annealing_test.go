@@ -0,0 +1,75 @@
+package guillotine
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func testAnnealing(seed int64) *SimulatedAnnealing {
+	r := rand.New(rand.NewSource(seed))
+	return &SimulatedAnnealing{
+		Spec:      NewRandomSpec(10, 40, 50, r, false),
+		Evaluator: (*LayoutTree).Area,
+		Mutator:   SwapMutator{Mean: 1, StdDev: 0.5}.Mutate,
+		Cooling:   GeometricCooling(0.9),
+		Moves:     20,
+		Levels:    15,
+		Restarts:  1,
+		R:         r,
+	}
+}
+
+func TestCooling(t *testing.T) {
+	if temp := GeometricCooling(0.5)(8, 2, 10); temp != 2 {
+		t.Errorf("Expected geometric cooling to reach 2, got %v", temp)
+	}
+	if temp := LinearCooling(8, 5, 10); temp != 4 {
+		t.Errorf("Expected linear cooling to reach 4, got %v", temp)
+	}
+	if temp := LogarithmicCooling(8, 0, 10); temp != 8 {
+		t.Errorf("Expected logarithmic cooling to start at 8, got %v", temp)
+	}
+	if _, err := ParseCooling("geometric", 1); err == nil {
+		t.Errorf("Expected an error for a rate that doesn't cool")
+	}
+	if _, err := ParseCooling("exponential", 0.9); err == nil {
+		t.Errorf("Expected an error for an unknown schedule")
+	}
+}
+
+func TestSimulatedAnnealing(t *testing.T) {
+	sa := testAnnealing(1)
+	var first, previous uint
+	sa.Observer = func(p *Progress) {
+		if first == 0 {
+			first = p.BestSoFar
+		}
+		if previous != 0 && p.BestSoFar > previous {
+			t.Errorf("Expected the best fitness to never get worse, got %d after %d", p.BestSoFar, previous)
+		}
+		previous = p.BestSoFar
+	}
+	result, err := sa.RunContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Generations != 30 || result.Evaluations != 1+temperatureSamples+30*20 {
+		t.Errorf("Expected 30 levels of 20 moves, got %d levels and %d evaluations",
+			result.Generations, result.Evaluations)
+	}
+	if result.Fitness >= first || result.Fitness != result.Layout.Area() {
+		t.Errorf("Expected the run to improve on %d, got %d", first, result.Fitness)
+	}
+	if result.Fitness < sa.Spec.AreaLowerBound() {
+		t.Errorf("Expected a fitness above the lower bound, got %d", result.Fitness)
+	}
+}
+
+func TestSimulatedAnnealingReproducible(t *testing.T) {
+	a, _ := testAnnealing(7).RunContext(context.Background())
+	b, _ := testAnnealing(7).RunContext(context.Background())
+	if a.Fitness != b.Fitness || a.Layout.Canonical() != b.Layout.Canonical() {
+		t.Errorf("Expected equally seeded runs to match, got %d and %d", a.Fitness, b.Fitness)
+	}
+}
@@ -0,0 +1,367 @@
+package guillotine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//A published cutting instance. Every instance is loaded as a strip of
+//width Spec.MaxWidth where all the boards must be placed. Strip packing
+//instances are compared by the layout Height, OR-Library ones by the
+//value of the boards cut from their stock Sheet, see Fitness.
+type Benchmark struct {
+	Name string
+	//Not saved along checkpoints, which hold the Spec themselves
+	Spec *CutSpec `json:"-"`
+	//Stock sheet of OR-Library instances, zero for strip packing ones
+	Sheet Board
+	//Value of each board of OR-Library instances, nil for strip packing
+	//ones
+	Values []uint `json:",omitempty"`
+	//Optimal or best known height, 0 when unknown. Strip packing files
+	//may include it, otherwise see ReadBestKnown.
+	BestKnown uint
+	//Published value of OR-Library instances, 0 when unknown. See
+	//PublishedValues.
+	BestValue uint `json:",omitempty"`
+}
+
+//Relative distance from height to the best known one.
+func (b *Benchmark) Gap(height uint) float64 {
+	if b.BestKnown == 0 {
+		return 0
+	}
+	return float64(height)/float64(b.BestKnown) - 1
+}
+
+//Whether the instance is a knapsack problem, an OR-Library one.
+func (b *Benchmark) Knapsack() bool {
+	return b.Values != nil
+}
+
+//Total value of the boards.
+func (b *Benchmark) TotalValue() uint {
+	var total uint
+	for _, v := range b.Values {
+		total += v
+	}
+	return total
+}
+
+//Value of the boards cut from the stock sheet: the ones lying entirely
+//within its height from the top of the strip, as the stock sheet is
+//that piece of the strip. Boards must keep the orientation of the
+//published problems, so rotated ones don't count unless they're square.
+//Cutting a guillotine layout at the sheet height leaves a guillotine
+//layout.
+func (b *Benchmark) Value(lt *LayoutTree) uint {
+	var value uint
+	for i, box := range NewDrawer(lt).Draw().Boxes {
+		board := b.Spec.Boards[i]
+		if lt.Picks[i].Rot && board.Width != board.Height {
+			continue
+		}
+		if box.Y+box.Height <= b.Sheet.Height {
+			value += b.Values[i]
+		}
+	}
+	return value
+}
+
+//Value of the boards left out of the stock sheet, which minimizes as
+//Value maximizes.
+func (b *Benchmark) LostValue(lt *LayoutTree) uint {
+	return b.TotalValue() - b.Value(lt)
+}
+
+//Relative distance from value to the published one.
+func (b *Benchmark) ValueGap(value uint) float64 {
+	if b.BestValue == 0 {
+		return 0
+	}
+	return 1 - float64(value)/float64(b.BestValue)
+}
+
+//Fitness to minimize when solving the instance: LostValue for knapsack
+//instances, the layout height otherwise.
+func (b *Benchmark) Fitness() Fitness {
+	if b.Knapsack() {
+		return b.LostValue
+	}
+	return (*LayoutTree).Height
+}
+
+//Fitness of the best known solution, 0 when unknown.
+func (b *Benchmark) Target() uint {
+	if !b.Knapsack() {
+		return b.BestKnown
+	}
+	if total := b.TotalValue(); b.BestValue > 0 && b.BestValue < total {
+		return total - b.BestValue
+	}
+	return 0
+}
+
+//Benchmark file formats:
+//
+//gcut, ngcut and cgcut are the OR-Library two dimensional cutting
+//instances (Beasley; Christofides and Whitlock). A problem is the
+//amount of piece types, the stock sheet length and width, and a line
+//per piece type starting with its length and width. For ngcut and
+//cgcut the next number is the most pieces of that type to cut, for
+//gcut there's a single one of each. The last number is the piece
+//value, pieces without one are worth their area. Files with several
+//problems start with the amount of problems.
+//
+//Those instances are knapsack problems, picking the most valuable
+//pieces that fit in the stock sheet without rotating them. Every piece
+//is placed on a strip as wide as the stock sheet here, and the ones
+//within the sheet height make up the solution value. Published values
+//are attached from PublishedValues.
+//
+//strip is the usual strip packing format: the amount of boards, the
+//strip width optionally followed by the optimal height, and a line per
+//board with its width and height, optionally preceded by an index.
+var BenchmarkFormats = []string{"gcut", "ngcut", "cgcut", "strip"}
+
+//Published optimal values of the OR-Library instances, by the names
+//they get when loading the OR-Library files: gcut1 to gcut13, ngcut-1
+//to ngcut-12 from the single ngcut file, and cgcut1 to cgcut3.
+//
+//Only the cgcut ones are for the same problem solved here. gcut values
+//are for unconstrained cutting, with any amount of each piece, and
+//ngcut ones allow non guillotine cuts, so both bound the values
+//guillotine layouts with the given pieces can reach.
+var PublishedValues = map[string]uint{
+	"gcut1":  56460,
+	"gcut2":  60076,
+	"gcut3":  61380,
+	"gcut4":  61380,
+	"gcut5":  246000,
+	"gcut6":  238998,
+	"gcut7":  242567,
+	"gcut8":  246633,
+	"gcut9":  971100,
+	"gcut10": 982025,
+	"gcut11": 980096,
+	"gcut12": 979986,
+	"gcut13": 8997780,
+
+	"ngcut-1":  164,
+	"ngcut-2":  230,
+	"ngcut-3":  247,
+	"ngcut-4":  268,
+	"ngcut-5":  358,
+	"ngcut-6":  289,
+	"ngcut-7":  430,
+	"ngcut-8":  834,
+	"ngcut-9":  924,
+	"ngcut-10": 1452,
+	"ngcut-11": 1688,
+	"ngcut-12": 1865,
+
+	"cgcut1": 244,
+	"cgcut2": 2892,
+	"cgcut3": 1860,
+}
+
+//Numbers in the non empty lines of a benchmark file.
+type numberLines struct {
+	lines [][]uint
+	next  int
+}
+
+func readNumberLines(r io.Reader) (*numberLines, error) {
+	nl := &numberLines{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		line := make([]uint, len(fields))
+		for i, f := range fields {
+			v, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", len(nl.lines)+1, err)
+			}
+			line[i] = uint(v)
+		}
+		nl.lines = append(nl.lines, line)
+	}
+	return nl, scanner.Err()
+}
+
+//Next line, which must have at least min numbers.
+func (nl *numberLines) line(min int) ([]uint, error) {
+	if nl.next >= len(nl.lines) {
+		return nil, fmt.Errorf("unexpected end of file")
+	}
+	line := nl.lines[nl.next]
+	nl.next++
+	if len(line) < min {
+		return nil, fmt.Errorf("line %d: expected %d numbers, got %d", nl.next, min, len(line))
+	}
+	return line, nil
+}
+
+func (nl *numberLines) add(spec *CutSpec, width, height, amount uint) error {
+	if !spec.Fits(width, height) {
+		return fmt.Errorf("line %d: board %dx%d doesn't fit in width %d", nl.next, width, height, spec.MaxWidth)
+	}
+	for ; amount > 0; amount-- {
+		spec.Add(width, height)
+	}
+	return nil
+}
+
+func (nl *numberLines) orLibrary(name, format string) (*Benchmark, error) {
+	header, err := nl.line(1)
+	if err != nil {
+		return nil, err
+	}
+	stock, err := nl.line(2)
+	if err != nil {
+		return nil, err
+	}
+	if stock[0] == 0 {
+		return nil, fmt.Errorf("line %d: stock sheet of width 0", nl.next)
+	}
+	b := &Benchmark{Name: name, Sheet: Board{stock[0], stock[1]}, BestValue: PublishedValues[name]}
+	b.Spec = newCutSpec(header[0], stock[0])
+	b.Values = make([]uint, 0, header[0])
+	for i := uint(0); i < header[0]; i++ {
+		columns := 3
+		if format == "gcut" {
+			columns = 2
+		}
+		piece, err := nl.line(columns)
+		if err != nil {
+			return nil, err
+		}
+		amount, value := uint(1), piece[0]*piece[1]
+		if format != "gcut" {
+			amount = piece[2]
+		}
+		if len(piece) > columns {
+			value = piece[len(piece)-1]
+		}
+		if err = nl.add(b.Spec, piece[0], piece[1], amount); err != nil {
+			return nil, err
+		}
+		for ; amount > 0; amount-- {
+			b.Values = append(b.Values, value)
+		}
+	}
+	return b, nil
+}
+
+func (nl *numberLines) strip(name string) (*Benchmark, error) {
+	header, err := nl.line(1)
+	if err != nil {
+		return nil, err
+	}
+	strip, err := nl.line(1)
+	if err != nil {
+		return nil, err
+	}
+	if strip[0] == 0 {
+		return nil, fmt.Errorf("line %d: strip of width 0", nl.next)
+	}
+	b := &Benchmark{Name: name, Spec: newCutSpec(header[0], strip[0])}
+	if len(strip) > 1 {
+		b.BestKnown = strip[1]
+	}
+	for i := uint(0); i < header[0]; i++ {
+		board, err := nl.line(2)
+		if err != nil {
+			return nil, err
+		}
+		board = board[len(board)-2:]
+		if err = nl.add(b.Spec, board[0], board[1], 1); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+//Every problem in a benchmark file of the given format, see
+//BenchmarkFormats. Problems are named after the file name, followed by
+//their position on files with several problems.
+func ReadBenchmarks(r io.Reader, format, name string) ([]*Benchmark, error) {
+	nl, err := readNumberLines(r)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "strip":
+		b, err := nl.strip(name)
+		if err != nil {
+			return nil, err
+		}
+		return []*Benchmark{b}, nil
+	case "gcut", "ngcut", "cgcut":
+		problems := 1
+		//a single problem has the stock size on its second line
+		multiple := len(nl.lines) > 1 && len(nl.lines[1]) == 1
+		if multiple {
+			problems = int(nl.lines[0][0])
+			nl.next++
+		}
+		benchmarks := make([]*Benchmark, problems)
+		for i := range benchmarks {
+			problem := name
+			if multiple {
+				problem = fmt.Sprintf("%s-%d", name, i+1)
+			}
+			if benchmarks[i], err = nl.orLibrary(problem, format); err != nil {
+				return nil, fmt.Errorf("%s: %v", problem, err)
+			}
+		}
+		return benchmarks, nil
+	}
+	return nil, fmt.Errorf("unknown benchmark format %q", format)
+}
+
+//Benchmarks in a file, named after the file without its extension.
+func LoadBenchmarks(path, format string) ([]*Benchmark, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ReadBenchmarks(f, format, name)
+}
+
+//Sets best known heights from lines of benchmark name and height.
+//Names without a benchmark are ignored.
+func ReadBestKnown(r io.Reader, benchmarks []*Benchmark) error {
+	byName := make(map[string]*Benchmark, len(benchmarks))
+	for _, b := range benchmarks {
+		byName[b.Name] = b
+	}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: expected a name and a height", line)
+		}
+		height, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if b, ok := byName[fields[0]]; ok {
+			b.BestKnown = uint(height)
+		}
+	}
+	return scanner.Err()
+}
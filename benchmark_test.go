@@ -0,0 +1,113 @@
+package guillotine
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestReadORLibrary(t *testing.T) {
+	gcut := "3\n250 250\n167 184 30728\n114 118 13452\n 167 152 25384\n"
+	benchmarks, err := ReadBenchmarks(strings.NewReader(gcut), "gcut", "gcut1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := benchmarks[0]
+	if len(benchmarks) != 1 || b.Name != "gcut1" || b.Sheet != (Board{250, 250}) {
+		t.Fatalf("Expected a single 250x250 gcut1 problem, got %d: %+v", len(benchmarks), b)
+	}
+	if len(b.Spec.Boards) != 3 || b.Spec.MaxWidth != 250 || b.Spec.Boards[1] != (Board{114, 118}) {
+		t.Errorf("Expected one board of each piece, got %+v", b.Spec)
+	}
+	if b.Spec.TotalArea != 167*184+114*118+167*152 {
+		t.Errorf("Expected the total area of the boards, got %d", b.Spec.TotalArea)
+	}
+}
+
+func TestReadORLibraryProblems(t *testing.T) {
+	ngcut := "2\n2\n10 10\n5 5 2\n3 4 1\n1\n20 8\n4 4 3\n"
+	benchmarks, err := ReadBenchmarks(strings.NewReader(ngcut), "ngcut", "ngcut")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(benchmarks) != 2 || benchmarks[1].Name != "ngcut-2" {
+		t.Fatalf("Expected 2 problems, got %d", len(benchmarks))
+	}
+	if n := len(benchmarks[0].Spec.Boards); n != 3 {
+		t.Errorf("Expected piece demands to be cut, got %d boards", n)
+	}
+	if b := benchmarks[1]; len(b.Spec.Boards) != 3 || b.Spec.MaxWidth != 20 {
+		t.Errorf("Expected 3 boards on a 20 wide strip, got %+v", b.Spec)
+	}
+	if _, err := ReadBenchmarks(strings.NewReader("1\n10 10\n11 12 1\n"), "cgcut", "c"); err == nil {
+		t.Errorf("Expected an error for a piece wider than the sheet")
+	}
+	if _, err := ReadBenchmarks(strings.NewReader("1\n0 10\n0 0 1\n"), "cgcut", "c"); err == nil {
+		t.Errorf("Expected an error for a sheet of width 0")
+	}
+	if b := benchmarks[0]; b.BestKnown != 0 {
+		t.Errorf("Expected no best known height for a relaxed knapsack instance, got %d", b.BestKnown)
+	}
+}
+
+func TestReadStrip(t *testing.T) {
+	strip := "3\n20 20\n1 20 4\n2 10 16\n3 10 16\n"
+	benchmarks, err := ReadBenchmarks(strings.NewReader(strip), "strip", "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := benchmarks[0]
+	if b.BestKnown != 20 || len(b.Spec.Boards) != 3 || b.Spec.Boards[2] != (Board{10, 16}) {
+		t.Errorf("Expected 3 boards and an optimal height of 20, got %+v", b)
+	}
+	if gap := b.Gap(22); gap < 0.099 || gap > 0.101 {
+		t.Errorf("Expected a 10%% gap, got %v", gap)
+	}
+	known := "# optimal heights\nc1 19\nother 3\n"
+	if err := ReadBestKnown(strings.NewReader(known), benchmarks); err != nil || b.BestKnown != 19 {
+		t.Errorf("Expected a best known height of 19, got %d (%v)", b.BestKnown, err)
+	}
+	if _, err := ReadBenchmarks(strings.NewReader("1\n0\n0 4\n"), "strip", "c"); err == nil {
+		t.Errorf("Expected an error for a strip of width 0")
+	}
+}
+
+func TestBenchmarkValue(t *testing.T) {
+	cgcut := "2\n10 4\n10 4 1 7\n10 6 1 5\n"
+	benchmarks, err := ReadBenchmarks(strings.NewReader(cgcut), "cgcut", "cgcut1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := benchmarks[0]
+	if !b.Knapsack() || b.TotalValue() != 12 || b.BestValue != PublishedValues["cgcut1"] {
+		t.Fatalf("Expected piece values and the published cgcut1 value, got %+v", b)
+	}
+	if target := b.Target(); target != 0 {
+		t.Errorf("Expected no target for a published value above the total, got %d", target)
+	}
+	values := make(map[uint]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		lt := GetPhenotype(b.Spec, NewRandomGenotype(2, r))
+		//only the 10x4 piece, unrotated on top, fits in the stock sheet
+		var expected uint
+		if box := NewDrawer(lt).Draw().Boxes[0]; box.Y == 0 && !lt.Picks[0].Rot {
+			expected = 7
+		}
+		if value := b.Value(lt); value != expected || b.Fitness()(lt) != 12-value {
+			t.Fatalf("Expected a value of %d, got %d and fitness %d", expected, value, b.Fitness()(lt))
+		}
+		values[expected]++
+	}
+	if values[0] == 0 || values[7] == 0 {
+		t.Errorf("Expected layouts both keeping and losing the piece, got %v", values)
+	}
+	b.BestValue = 10
+	if b.Target() != 2 || b.ValueGap(5) != 0.5 {
+		t.Errorf("Expected a target of 2 and a 50%% gap, got %d and %v", b.Target(), b.ValueGap(5))
+	}
+	gcut := "1\n10 10\n5 5\n"
+	if benchmarks, err = ReadBenchmarks(strings.NewReader(gcut), "gcut", "g"); err != nil || benchmarks[0].Values[0] != 25 {
+		t.Errorf("Expected pieces without a value to be worth their area, got %+v (%v)", benchmarks, err)
+	}
+}
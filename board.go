@@ -62,3 +62,29 @@ func (spec *CutSpec) Add(width, height uint) *CutSpec {
 func newCutSpec(nboards uint, maxWidth uint) *CutSpec {
 	return &CutSpec{Boards: make([]Board, 0, nboards), MaxWidth: maxWidth}
 }
+
+//No layout can have an area smaller than the boards themselves
+func (spec *CutSpec) AreaLowerBound() uint {
+	return spec.TotalArea
+}
+
+//Lowest possible height on a MaxWidth wide sheet: the boards area
+//spread across the whole width, and no less than the shortest
+//orientation of any board that fits.
+func (spec *CutSpec) HeightLowerBound() uint {
+	if spec.MaxWidth == 0 {
+		return 0
+	}
+	bound := (spec.TotalArea + spec.MaxWidth - 1) / spec.MaxWidth
+	for _, b := range spec.Boards {
+		short, long := b.Width, b.Height
+		if short > long {
+			short, long = long, short
+		}
+		if long > spec.MaxWidth {
+			short = long
+		}
+		bound = max(bound, short)
+	}
+	return bound
+}
@@ -0,0 +1,75 @@
+package guillotine
+
+import (
+	"math/rand"
+)
+
+//Biased uniform crossover: c1 inherits every gene from p1 with
+//probability Rho, and from p2 otherwise. c2 gets the remaining genes.
+type BiasedCrossover struct {
+	Rho float64
+}
+
+func (bc BiasedCrossover) Crossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
+	n, c1, c2 := freshPair(p1, p2)
+	for i := 0; i < n; i++ {
+		if r.Float64() < bc.Rho {
+			c1[i], c2[i] = p1[i], p2[i]
+		} else {
+			c1[i], c2[i] = p2[i], p1[i]
+		}
+	}
+	return
+}
+
+var _ Crossover = BiasedCrossover{}.Crossover
+
+//Biased random key genetic algorithm: the fittest Elite fraction of
+//the population is copied to the next generation, a Mutants fraction
+//is made of new random genotypes, and the rest are children of an
+//elite and a non elite parent, picked at random, through a
+//BiasedCrossover of rate Rho. Children aren't mutated.
+type BRKGA struct {
+	Elite   float64
+	Mutants float64
+	Rho     float64
+}
+
+//Amount of elites and mutants of a population of size n. There's at
+//least one elite, and one non elite parent when there are children.
+//Fractions out of [0, 1] are clamped.
+func (b *BRKGA) sizes(n int) (elite, mutants int) {
+	elite = int(b.Elite * float64(n))
+	if elite < 1 {
+		elite = 1
+	} else if elite > n {
+		elite = n
+	}
+	mutants = int(b.Mutants * float64(n))
+	if mutants < 0 {
+		mutants = 0
+	} else if mutants > n-elite {
+		mutants = n - elite
+	}
+	return
+}
+
+//Next generation of a BRKGA, see BRKGA. Mutator, Breeder,
+//SelectorBuilder and EliteSize are ignored.
+func (ga *GeneticAlgorithm) nextBRKGA(rp *RankedPopulation) Population {
+	n := len(rp.Pop)
+	elite, mutants := ga.BRKGA.sizes(n)
+	next := make(Population, n)
+	copy(next[:elite], rp.Pop[:elite])
+	crossover := BiasedCrossover{ga.BRKGA.Rho}
+	for i := elite; i < n-mutants; i++ {
+		p1 := rp.Pop[ga.R.Intn(elite)]
+		p2 := rp.Pop[elite+ga.R.Intn(n-elite)]
+		next[i], _ = crossover.Crossover(p1, p2, ga.R)
+	}
+	nboards := uint16(len(ga.Spec.Boards))
+	for i := n - mutants; i < n; i++ {
+		next[i] = NewRandomGenotype(nboards, ga.R)
+	}
+	return next
+}
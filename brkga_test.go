@@ -0,0 +1,69 @@
+package guillotine
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestBiasedCrossover(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	p1, p2 := NewRandomGenotype(8, r), NewRandomGenotype(8, r)
+	c1, c2 := BiasedCrossover{Rho: 1}.Crossover(p1, p2, r)
+	for i := range p1 {
+		if c1[i] != p1[i] || c2[i] != p2[i] {
+			t.Fatalf("Expected every gene from the first parent at %d", i)
+		}
+	}
+	var inherited int
+	c1, _ = BiasedCrossover{Rho: 0.8}.Crossover(p1, p2, r)
+	for i := range p1 {
+		if c1[i] == p1[i] {
+			inherited++
+		}
+	}
+	if inherited < len(p1)/2 || inherited == len(p1) {
+		t.Errorf("Expected most genes from the first parent, got %d of %d", inherited, len(p1))
+	}
+}
+
+func TestBRKGASizes(t *testing.T) {
+	b := &BRKGA{Elite: 0.2, Mutants: 0.15, Rho: 0.7}
+	if elite, mutants := b.sizes(20); elite != 4 || mutants != 3 {
+		t.Errorf("Expected 4 elites and 3 mutants, got %d and %d", elite, mutants)
+	}
+	b = &BRKGA{Elite: 0, Mutants: 1}
+	if elite, mutants := b.sizes(20); elite != 1 || mutants != 19 {
+		t.Errorf("Expected a single elite and 19 mutants, got %d and %d", elite, mutants)
+	}
+	b = &BRKGA{Elite: 0.2, Mutants: -0.1}
+	if elite, mutants := b.sizes(20); elite != 4 || mutants != 0 {
+		t.Errorf("Expected 4 elites and no mutants, got %d and %d", elite, mutants)
+	}
+}
+
+func TestBRKGA(t *testing.T) {
+	ga := testGA(rand.New(rand.NewSource(1)), 8)
+	ga.Generations = 30
+	ga.BRKGA = &BRKGA{Elite: 0.2, Mutants: 0.15, Rho: 0.7}
+	var first, previous uint
+	ga.Observer = func(p *Progress) {
+		if first == 0 {
+			first = p.Best
+		}
+		if previous != 0 && p.Best > previous {
+			t.Errorf("Expected elites to be kept, got %d after %d", p.Best, previous)
+		}
+		previous = p.Best
+		if n := len(p.Population.Pop); n != 20 {
+			t.Errorf("Expected the population size to be kept, got %d", n)
+		}
+	}
+	result, err := ga.RunContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Fitness >= first {
+		t.Errorf("Expected the run to improve on %d, got %d", first, result.Fitness)
+	}
+}
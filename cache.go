@@ -0,0 +1,132 @@
+package guillotine
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+//Hash of the genotype genes, equal genotypes share the same hash.
+func (g Genotype) Hash() uint64 {
+	h := fnv.New64a()
+	var b [9]byte
+	for _, wj := range g {
+		binary.LittleEndian.PutUint32(b[0:], math.Float32bits(wj.weight))
+		binary.LittleEndian.PutUint16(b[4:], wj.i)
+		binary.LittleEndian.PutUint16(b[6:], wj.j)
+		b[8] = byte(wj.config)
+		h.Write(b[:])
+	}
+	return h.Sum64()
+}
+
+type CacheStats struct {
+	Hits, Misses uint
+	Size         int
+}
+
+//Fraction of lookups that found a fitness, 0 when there were none.
+func (cs CacheStats) HitRate() float64 {
+	if cs.Hits+cs.Misses == 0 {
+		return 0
+	}
+	return float64(cs.Hits) / float64(cs.Hits+cs.Misses)
+}
+
+type cacheEntry struct {
+	key      uint64
+	genotype Genotype
+	fitness  uint
+}
+
+//Whether the entry is for g and not for another genotype whose hash
+//collides with its own.
+func (e *cacheEntry) holds(g Genotype) bool {
+	if len(e.genotype) != len(g) {
+		return false
+	}
+	for i := range g {
+		if e.genotype[i] != g[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//Fitnesses by genotype, so elites and children identical to their
+//parents aren't decoded and evaluated again. Entries are found by the
+//genotype Hash, and keep a copy of the genotype to tell collisions
+//apart. Holds up to Capacity entries, evicting the least recently used
+//ones. Safe for concurrent use. A cache must only be shared by runs
+//with the same Spec and Evaluator.
+type FitnessCache struct {
+	Capacity int
+	mu       sync.Mutex
+	entries  map[uint64]*list.Element
+	order    *list.List
+	stats    CacheStats
+}
+
+func NewFitnessCache(capacity int) *FitnessCache {
+	return &FitnessCache{
+		Capacity: capacity,
+		entries:  make(map[uint64]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (fc *FitnessCache) Get(g Genotype) (fitness uint, ok bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	e, ok := fc.entries[g.Hash()]
+	if !ok || !e.Value.(*cacheEntry).holds(g) {
+		fc.stats.Misses++
+		return 0, false
+	}
+	fc.stats.Hits++
+	fc.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).fitness, true
+}
+
+//Remembers the fitness of g, replacing the entry of any other genotype
+//with the same hash.
+func (fc *FitnessCache) Put(g Genotype, fitness uint) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	key := g.Hash()
+	if e, ok := fc.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		if !entry.holds(g) {
+			entry.genotype = g.copy()
+		}
+		entry.fitness = fitness
+		fc.order.MoveToFront(e)
+		return
+	}
+	fc.entries[key] = fc.order.PushFront(&cacheEntry{key, g.copy(), fitness})
+	for fc.order.Len() > fc.Capacity {
+		last := fc.order.Back()
+		delete(fc.entries, last.Value.(*cacheEntry).key)
+		fc.order.Remove(last)
+	}
+}
+
+func (fc *FitnessCache) Stats() CacheStats {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	stats := fc.stats
+	stats.Size = fc.order.Len()
+	return stats
+}
+
+//Fitness of the genotype, from the cache when it's been seen before.
+func (fc *FitnessCache) fitness(spec *CutSpec, evaluator Fitness, g Genotype) uint {
+	if fitness, ok := fc.Get(g); ok {
+		return fitness
+	}
+	fitness := evaluator(GetPhenotype(spec, g))
+	fc.Put(g, fitness)
+	return fitness
+}
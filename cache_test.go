@@ -0,0 +1,77 @@
+package guillotine
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestFitnessCacheEviction(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g1, g2, g3 := NewRandomGenotype(6, r), NewRandomGenotype(6, r), NewRandomGenotype(6, r)
+	fc := NewFitnessCache(2)
+	fc.Put(g1, 10)
+	fc.Put(g2, 20)
+	fc.Get(g1)
+	fc.Put(g3, 30)
+	if _, ok := fc.Get(g2); ok {
+		t.Errorf("Expected the least recently used entry to be evicted")
+	}
+	if f, ok := fc.Get(g1); !ok || f != 10 {
+		t.Errorf("Expected a cached fitness of 10, got %d", f)
+	}
+	stats := fc.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Size != 2 {
+		t.Errorf("Expected 2 hits, 1 miss and 2 entries, got %+v", stats)
+	}
+}
+
+func TestFitnessCacheCollision(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g, other := NewRandomGenotype(6, r), NewRandomGenotype(6, r)
+	fc := NewFitnessCache(2)
+	fc.Put(g, 10)
+	//pretend the entry for g's hash belongs to another genotype
+	fc.entries[g.Hash()].Value.(*cacheEntry).genotype = other
+	if _, ok := fc.Get(g); ok {
+		t.Errorf("Expected a colliding genotype to miss")
+	}
+	fc.Put(g, 20)
+	if f, ok := fc.Get(g); !ok || f != 20 || fc.Stats().Size != 1 {
+		t.Errorf("Expected the colliding entry to be replaced, got %d", f)
+	}
+}
+
+func TestGenotypeHash(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := NewRandomGenotype(6, r)
+	c := g.copy()
+	if g.Hash() != c.Hash() {
+		t.Errorf("Expected equal genotypes to share their hash")
+	}
+	c[3].config = (c[3].config + 1) % 8
+	if g.Hash() == c.Hash() {
+		t.Errorf("Expected a different hash for a different config")
+	}
+}
+
+func TestCachedRun(t *testing.T) {
+	run := func(cache *FitnessCache) *RunResult {
+		ga := testGA(rand.New(rand.NewSource(5)), 8)
+		ga.Cache = cache
+		result, err := ga.RunContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	cache := NewFitnessCache(100)
+	plain, cached := run(nil), run(cache)
+	if plain.Fitness != cached.Fitness || plain.Layout.Canonical() != cached.Layout.Canonical() {
+		t.Errorf("Expected the cache not to change the run, got fitness %d and %d", plain.Fitness, cached.Fitness)
+	}
+	//elites alone are hits on every generation after the first one
+	if stats := cache.Stats(); stats.Hits < 2*(cached.Generations-1) || stats.Size > 100 {
+		t.Errorf("Expected elites to be cached, got %+v", stats)
+	}
+}
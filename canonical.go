@@ -0,0 +1,77 @@
+package guillotine
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/rdarder/guillotine/evolve"
+)
+
+//Canonical form of the layout. Many trees describe the same physical
+//layout: children of a stack can be listed in any order (which covers
+//mirrored layouts), nested stacks in the same direction can be grouped
+//in any way, and boards with the same dimensions are interchangeable.
+//The canonical form flattens same direction stacks, identifies boards
+//by their oriented dimensions and sorts the children of every stack,
+//so equivalent layouts share it.
+func (lt *LayoutTree) Canonical() string {
+	return lt.canonical(2*lt.Nboards - 2)
+}
+
+func (lt *LayoutTree) canonical(i uint16) string {
+	if i < lt.Nboards {
+		b := lt.getBoard(i, lt.Spec.Boards, lt.Areas)
+		return fmt.Sprintf("%dx%d", b.Width, b.Height)
+	}
+	direction := lt.Stacks[i-lt.Nboards].Direction
+	operands := lt.stackOperands(i, direction, nil)
+	sort.Strings(operands)
+	var op string
+	if direction == VERTICAL {
+		op = "V("
+	} else {
+		op = "H("
+	}
+	return op + strings.Join(operands, ",") + ")"
+}
+
+//Canonical forms of the operands of the stack rooted at i, looking
+//through nested stacks in the same direction.
+func (lt *LayoutTree) stackOperands(i uint16, direction Direction, operands []string) []string {
+	if i >= lt.Nboards {
+		if node := lt.Stacks[i-lt.Nboards]; node.Direction == direction {
+			operands = lt.stackOperands(node.Left, direction, operands)
+			return lt.stackOperands(node.Right, direction, operands)
+		}
+	}
+	return append(operands, lt.canonical(i))
+}
+
+//Hash of the canonical form, equivalent layouts share the same hash.
+func (lt *LayoutTree) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(lt.Canonical()))
+	return h.Sum64()
+}
+
+//Keeps the first, best ranked, individual of every distinct layout.
+func DistinctLayouts(spec *CutSpec, rp *RankedPopulation) *RankedPopulation {
+	return evolve.Distinct(rp, func(g Genotype) uint64 {
+		return GetPhenotype(spec, g).Hash()
+	})
+}
+
+//Up to k best layouts of a ranked population, no two of them equivalent.
+func (ga *GeneticAlgorithm) Alternatives(rp *RankedPopulation, k int) []*LayoutTree {
+	distinct := DistinctLayouts(ga.Spec, rp)
+	if k > len(distinct.Pop) {
+		k = len(distinct.Pop)
+	}
+	layouts := make([]*LayoutTree, k)
+	for i := range layouts {
+		layouts[i] = GetPhenotype(ga.Spec, distinct.Pop[i])
+	}
+	return layouts
+}
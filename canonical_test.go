@@ -0,0 +1,38 @@
+package guillotine
+
+import "testing"
+
+func TestCanonicalMirrorAndSwap(t *testing.T) {
+	spec := newCutSpec(0, 0).Add(2, 3).Add(2, 3).Add(4, 1)
+	lt1 := NewLayoutTree(spec)
+	lt1.take(0, 1, JOIN.direct(HORIZONTAL))
+	lt1.take(0, 2, JOIN.direct(VERTICAL))
+	lt2 := NewLayoutTree(spec)
+	lt2.take(1, 0, JOIN.direct(HORIZONTAL))
+	lt2.take(2, 1, JOIN.direct(VERTICAL))
+	if c1, c2 := lt1.Canonical(), lt2.Canonical(); c1 != c2 {
+		t.Errorf("Expected equivalent layouts, got [%v] and [%v]", c1, c2)
+	}
+	if lt1.Hash() != lt2.Hash() {
+		t.Error("Expected equivalent layouts to share hash")
+	}
+	lt3 := NewLayoutTree(spec)
+	lt3.take(0, 1, JOIN.direct(VERTICAL))
+	lt3.take(0, 2, JOIN.direct(VERTICAL))
+	if lt1.Hash() == lt3.Hash() {
+		t.Errorf("Expected different layouts, got [%v] for both", lt1.Canonical())
+	}
+}
+
+func TestCanonicalNestedStacks(t *testing.T) {
+	spec := newCutSpec(0, 0).Add(1, 2).Add(3, 4).Add(5, 6)
+	lt1 := NewLayoutTree(spec)
+	lt1.take(0, 1, JOIN.direct(HORIZONTAL))
+	lt1.take(1, 2, JOIN.direct(HORIZONTAL))
+	lt2 := NewLayoutTree(spec)
+	lt2.take(1, 2, JOIN.direct(HORIZONTAL))
+	lt2.take(0, 1, JOIN.direct(HORIZONTAL))
+	if c1, c2 := lt1.Canonical(), lt2.Canonical(); c1 != c2 || c1 != "H(1x2,3x4,5x6)" {
+		t.Errorf("Expected H(1x2,3x4,5x6) for both layouts, got [%v] and [%v]", c1, c2)
+	}
+}
@@ -0,0 +1,199 @@
+package guillotine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//A math/rand Source that reseeds itself on every generation of a
+//GeneticAlgorithm run, with a seed derived from its own seed and the
+//generation. Its state is then just the seed and the generation, so it
+//can be saved and restored without replaying the values it produced.
+//Until the first Reseed, values are the same as the ones from
+//rand.NewSource for that seed.
+type Source struct {
+	seed       int64
+	generation uint
+	src        rand.Source64
+}
+
+func NewSource(seed int64) *Source {
+	return &Source{seed: seed, src: rand.NewSource(seed).(rand.Source64)}
+}
+
+//Recreates a Source in the state it was at the given generation.
+func RestoreSource(seed int64, generation uint) *Source {
+	s := NewSource(seed)
+	if generation > 0 {
+		s.Reseed(generation)
+	}
+	return s
+}
+
+//Seed of the given generation of a run seeded with seed, mixed like
+//splitmix64 does so that close generations get unrelated seeds.
+func generationSeed(seed int64, generation uint) int64 {
+	z := uint64(seed) + uint64(generation)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+//Starts drawing the values of the given generation.
+func (s *Source) Reseed(generation uint) {
+	s.generation = generation
+	s.src.Seed(generationSeed(s.seed, generation))
+}
+
+func (s *Source) Int63() int64 {
+	return s.src.Int63()
+}
+
+func (s *Source) Uint64() uint64 {
+	return s.src.Uint64()
+}
+
+func (s *Source) Seed(seed int64) {
+	s.seed, s.generation = seed, 0
+	s.src.Seed(seed)
+}
+
+func (s *Source) State() (seed int64, generation uint) {
+	return s.seed, s.generation
+}
+
+var _ rand.Source64 = (*Source)(nil)
+
+//Everything needed to continue a GeneticAlgorithm run from the end
+//of a generation, as if it was never interrupted.
+type Checkpoint struct {
+	Spec        *CutSpec
+	Seed        int64
+	Generation  uint
+	Evaluations uint
+	Elapsed     time.Duration
+	BestSoFar   uint
+	Improved    uint
+	BestLayout  *LayoutTree
+	Population  Population
+	Fitnesses   []uint
+	//State of the Adapter when it's an AdaptiveMutator, so resumed runs
+	//continue from the same mutation means.
+	Adaptive *AdaptiveMutator `json:",omitempty"`
+	//Benchmark being solved, nil for random specs. It tells resumed
+	//runs to minimize the benchmark Fitness instead of area.
+	Benchmark *Benchmark `json:",omitempty"`
+	//Generations collected by a StatsCollector, set by its owner, so
+	//resumed runs export the stats of the whole run.
+	Stats []GenerationStats `json:",omitempty"`
+}
+
+//Captures the state of the run after the generation in p. Only valid
+//while the run is stopped at p, which is the case within Observers.
+func (ga *GeneticAlgorithm) Checkpoint(p *Progress) (*Checkpoint, error) {
+	if ga.Source == nil {
+		return nil, fmt.Errorf("checkpoints need the GeneticAlgorithm Source")
+	}
+	seed, _ := ga.Source.State()
+	cp := &Checkpoint{
+		Spec:        ga.Spec,
+		Seed:        seed,
+		Generation:  p.Generation,
+		Evaluations: p.Evaluations,
+		Elapsed:     p.Elapsed,
+		BestSoFar:   p.BestSoFar,
+		Improved:    p.Improved,
+		BestLayout:  p.BestPhenotype,
+		Population:  p.Population.Pop,
+		Fitnesses:   p.Population.Fitnesses,
+	}
+	if am, ok := ga.Adapter.(*AdaptiveMutator); ok {
+		saved := *am
+		cp.Adaptive = &saved
+	}
+	return cp, nil
+}
+
+//Source in the state it was when the checkpoint was taken. The resumed
+//GeneticAlgorithm's R must draw from it.
+func (cp *Checkpoint) Source() *Source {
+	return RestoreSource(cp.Seed, cp.Generation)
+}
+
+//Continues a checkpointed run. The GeneticAlgorithm should be set up
+//like the original one, with cp.Spec as its Spec and cp.Source() as
+//its Source. An AdaptiveMutator Adapter is restored to its saved state.
+func (ga *GeneticAlgorithm) ResumeContext(ctx context.Context, cp *Checkpoint) (*RunResult, error) {
+	if am, ok := ga.Adapter.(*AdaptiveMutator); ok && cp.Adaptive != nil {
+		*am = *cp.Adaptive
+	}
+	start := time.Now().Add(-cp.Elapsed)
+	p := &Progress{
+		Generation:    cp.Generation,
+		Evaluations:   cp.Evaluations,
+		Elapsed:       cp.Elapsed,
+		BestSoFar:     cp.BestSoFar,
+		BestPhenotype: cp.BestLayout,
+		Improved:      cp.Improved,
+	}
+	ga.engine().Rank(p, &RankedPopulation{Pop: cp.Population, Fitnesses: cp.Fitnesses})
+	return ga.evolve(ctx, p, start, ga.stopCondition())
+}
+
+func (cp *Checkpoint) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(cp)
+}
+
+func ReadCheckpoint(r io.Reader) (*Checkpoint, error) {
+	cp := &Checkpoint{}
+	if err := json.NewDecoder(r).Decode(cp); err != nil {
+		return nil, err
+	}
+	if cp.Spec == nil || len(cp.Population) == 0 || len(cp.Population) != len(cp.Fitnesses) {
+		return nil, fmt.Errorf("invalid checkpoint")
+	}
+	if cp.BestLayout != nil {
+		cp.BestLayout.Spec = cp.Spec
+	}
+	if cp.Benchmark != nil {
+		cp.Benchmark.Spec = cp.Spec
+	}
+	return cp, nil
+}
+
+//Writes the checkpoint to a file, replacing it only once the new
+//checkpoint is complete.
+func (cp *Checkpoint) Save(name string) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if err = cp.Write(f); err == nil {
+		f.Chmod(0644)
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func LoadCheckpoint(name string) (*Checkpoint, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCheckpoint(f)
+}
@@ -0,0 +1,120 @@
+package guillotine
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func checkpointGA(spec *CutSpec, src *Source) *GeneticAlgorithm {
+	r := rand.New(src)
+	return &GeneticAlgorithm{
+		Spec:      spec,
+		Evaluator: (*LayoutTree).Area,
+		Mutator: func(g Genotype, r *rand.Rand) {
+			g[r.Intn(len(g))].weight = r.Float32()
+		},
+		Breeder:         UniformCrossover,
+		SelectorBuilder: NewTournamentSelectorBuilder(4, 0.7, r, true),
+		R:               r,
+		Source:          src,
+		EliteSize:       2,
+		PopulationSize:  20,
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	spec := NewRandomSpec(10, 40, 50, rand.New(rand.NewSource(1)), false)
+	ga := checkpointGA(spec, NewSource(7))
+	ga.Stop = MaxGenerations(12)
+	stats := NewStatsCollector(spec)
+	ga.Observer = stats.Observe
+	expected, _ := ga.RunContext(context.Background())
+
+	var saved bytes.Buffer
+	ga = checkpointGA(spec, NewSource(7))
+	ga.Stop = MaxGenerations(5)
+	collector := NewStatsCollector(spec)
+	ga.Observer = func(p *Progress) {
+		collector.Observe(p)
+		if p.Generation == 5 {
+			cp, err := ga.Checkpoint(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cp.Benchmark = &Benchmark{Name: "random", Spec: spec, BestKnown: 40}
+			cp.Stats = collector.Generations
+			cp.Write(&saved)
+		}
+	}
+	ga.RunContext(context.Background())
+
+	cp, err := ReadCheckpoint(&saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := cp.Benchmark; b == nil || b.Name != "random" || b.BestKnown != 40 || b.Spec != cp.Spec {
+		t.Errorf("Expected the benchmark to be restored along the checkpoint, got %+v", b)
+	}
+	if seed, generation := cp.Source().State(); seed != 7 || generation != 5 {
+		t.Errorf("Expected the source of generation 5 of seed 7, got %d and %d", seed, generation)
+	}
+	ga = checkpointGA(cp.Spec, cp.Source())
+	ga.Stop = MaxGenerations(12)
+	collector = &StatsCollector{Spec: cp.Spec, Generations: cp.Stats}
+	ga.Observer = collector.Observe
+	resumed, _ := ga.ResumeContext(context.Background(), cp)
+	if len(collector.Generations) != 12 {
+		t.Fatalf("Expected the stats of all 12 generations, got %d", len(collector.Generations))
+	}
+	for i, gs := range collector.Generations {
+		if want := stats.Generations[i]; gs.Best != want.Best || gs.Mean != want.Mean || gs.Evaluations != want.Evaluations {
+			t.Errorf("Expected the stats of generation %d to match the uninterrupted run, got %+v and %+v", i+1, gs, want)
+		}
+	}
+	if resumed.Generations != 12 || resumed.Fitness != expected.Fitness {
+		t.Errorf("Expected fitness %v after 12 generations, got %v after %v",
+			expected.Fitness, resumed.Fitness, resumed.Generations)
+	}
+	if !reflect.DeepEqual(expected.Population, resumed.Population) {
+		t.Error("Expected resumed run to end with the same population")
+	}
+}
+
+func TestCheckpointAdaptive(t *testing.T) {
+	spec := NewRandomSpec(10, 40, 50, rand.New(rand.NewSource(1)), false)
+	adaptiveGA := func(src *Source) (*GeneticAlgorithm, *AdaptiveMutator) {
+		ga := checkpointGA(spec, src)
+		mutator := NewAdaptiveMutator(4, 4)
+		ga.Mutator, ga.Adapter = mutator.Mutate, mutator
+		return ga, mutator
+	}
+	ga, expected := adaptiveGA(NewSource(7))
+	ga.Stop = MaxGenerations(12)
+	ga.RunContext(context.Background())
+
+	var saved bytes.Buffer
+	ga, _ = adaptiveGA(NewSource(7))
+	ga.Stop = MaxGenerations(5)
+	ga.Observer = func(p *Progress) {
+		if p.Generation == 5 {
+			cp, _ := ga.Checkpoint(p)
+			cp.Write(&saved)
+		}
+	}
+	ga.RunContext(context.Background())
+
+	cp, err := ReadCheckpoint(&saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ga, resumed := adaptiveGA(cp.Source())
+	ga.Stop = MaxGenerations(12)
+	ga.ResumeContext(context.Background(), cp)
+	if !reflect.DeepEqual(expected.Trajectory, resumed.Trajectory) {
+		t.Errorf("Expected the resumed mutation means to follow the original ones, got %+v instead of %+v",
+			resumed.Trajectory, expected.Trajectory)
+	}
+}
@@ -0,0 +1,161 @@
+package guillotine
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+//Crossovers that treat weights as real valued random keys, instead of
+//swapping whole genes. Children configs are inherited gene by gene from
+//either parent, like UniformCrossover does.
+
+//Weight back within [0, 1], reflected on the bounds so values that
+//overshoot don't pile up on ties. Non finite values are clamped.
+func bounded(x float64) float32 {
+	x = math.Abs(x)
+	if math.IsNaN(x) {
+		return 0
+	} else if math.IsInf(x, 1) {
+		return 1
+	}
+	x = math.Mod(x, 2)
+	if x > 1 {
+		x = 2 - x
+	}
+	return float32(x)
+}
+
+//Fresh children with the same joins as their parents, configs taken
+//from one parent or the other at random.
+func keyedPair(p1, p2 Genotype, r *rand.Rand) (n int, c1, c2 Genotype) {
+	n, c1, c2 = freshPair(p1, p2)
+	for i := 0; i < n; {
+		rs := r.Int63()
+		for j := uint(0); i < n && j < 63; j++ {
+			c1[i], c2[i] = p1[i], p2[i]
+			if (rs & (1 << j)) != 0 {
+				c1[i].config, c2[i].config = p2[i].config, p1[i].config
+			}
+			i++
+		}
+	}
+	return
+}
+
+//BLX-alpha: every child weight is drawn uniformly from the parents'
+//interval, stretched on both sides by Alpha times its length.
+type BlendCrossover struct {
+	Alpha float64
+}
+
+func (bc BlendCrossover) Crossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
+	n, c1, c2 := keyedPair(p1, p2, r)
+	for i := 0; i < n; i++ {
+		lo, hi := float64(p1[i].weight), float64(p2[i].weight)
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		d := bc.Alpha * (hi - lo)
+		lo, hi = lo-d, hi+d
+		c1[i].weight = bounded(lo + r.Float64()*(hi-lo))
+		c2[i].weight = bounded(lo + r.Float64()*(hi-lo))
+	}
+	return
+}
+
+var _ Crossover = BlendCrossover{}.Crossover
+
+//Whole arithmetic crossover: children are lambda*p1 + (1-lambda)*p2 and
+//(1-lambda)*p1 + lambda*p2, with a random lambda for every mating.
+func ArithmeticCrossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
+	n, c1, c2 := keyedPair(p1, p2, r)
+	lambda := r.Float32()
+	for i := 0; i < n; i++ {
+		w1, w2 := p1[i].weight, p2[i].weight
+		c1[i].weight = lambda*w1 + (1-lambda)*w2
+		c2[i].weight = (1-lambda)*w1 + lambda*w2
+	}
+	return
+}
+
+var _ Crossover = ArithmeticCrossover
+
+//Simulated binary crossover: children weights are spread around the
+//parents' ones as a one point crossover of their binary representation
+//would. Higher Eta values keep children closer to their parents, it
+//must be positive.
+type SBXCrossover struct {
+	Eta float64
+}
+
+func (sc SBXCrossover) Crossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
+	n, c1, c2 := keyedPair(p1, p2, r)
+	exp := 1 / (sc.Eta + 1)
+	for i := 0; i < n; i++ {
+		var beta float64
+		if u := r.Float64(); u <= 0.5 {
+			beta = math.Pow(2*u, exp)
+		} else {
+			beta = math.Pow(1/(2*(1-u)), exp)
+		}
+		w1, w2 := float64(p1[i].weight), float64(p2[i].weight)
+		c1[i].weight = bounded(0.5 * ((1+beta)*w1 + (1-beta)*w2))
+		c2[i].weight = bounded(0.5 * ((1-beta)*w1 + (1+beta)*w2))
+	}
+	return
+}
+
+var _ Crossover = SBXCrossover{}.Crossover
+
+//Genes indices, from the lowest to the highest weight.
+func ranking(g Genotype) []int {
+	order := make([]int, len(g))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return g[order[a]].weight < g[order[b]].weight
+	})
+	return order
+}
+
+//Order crossover over the weight ranking, which is what decides the
+//layout. Each child keeps a random segment of one parent's ranking in
+//place, and ranks the remaining genes in the order they have in the
+//other parent. Weights are the ranked parent's ones, so only the order
+//of the joins changes. Configs come with the genes.
+func OrderCrossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
+	n, c1, c2 := freshPair(p1, p2)
+	start, end := r.Intn(n), r.Intn(n)
+	if start > end {
+		start, end = end, start
+	}
+	end++
+	rank1, rank2 := ranking(p1), ranking(p2)
+	orderChild(c1, p1, p2, rank1, rank2, start, end)
+	orderChild(c2, p2, p1, rank2, rank1, start, end)
+	return
+}
+
+func orderChild(c, keep, fill Genotype, keepRank, fillRank []int, start, end int) {
+	kept := make([]bool, len(c))
+	for _, gene := range keepRank[start:end] {
+		kept[gene] = true
+		c[gene] = keep[gene]
+	}
+	rank := 0
+	for _, gene := range fillRank {
+		if kept[gene] {
+			continue
+		}
+		if rank == start {
+			rank = end
+		}
+		c[gene] = fill[gene]
+		c[gene].weight = keep[keepRank[rank]].weight
+		rank++
+	}
+}
+
+var _ Crossover = OrderCrossover
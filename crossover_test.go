@@ -0,0 +1,118 @@
+package guillotine
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var keyCrossovers = map[string]Crossover{
+	"blend":      BlendCrossover{Alpha: 0.5}.Crossover,
+	"arithmetic": ArithmeticCrossover,
+	"sbx":        SBXCrossover{Eta: 2}.Crossover,
+	"order":      OrderCrossover,
+}
+
+func TestKeyCrossoversKeepJoins(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for name, crossover := range keyCrossovers {
+		for k := 0; k < 20; k++ {
+			p1, p2 := NewRandomGenotype(8, r), NewRandomGenotype(8, r)
+			c1, c2 := crossover(p1, p2, r)
+			for _, c := range []Genotype{c1, c2} {
+				for i, wj := range c {
+					if wj.i != p1[i].i || wj.j != p1[i].j {
+						t.Fatalf("Expected %s to keep join %d in place, got %d-%d", name, i, wj.i, wj.j)
+					}
+					if wj.config != p1[i].config && wj.config != p2[i].config {
+						t.Fatalf("Expected %s configs to come from a parent", name)
+					}
+					if wj.weight < 0 || wj.weight > 1 {
+						t.Fatalf("Expected %s weights within [0, 1], got %v", name, wj.weight)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBlendCrossoverInterval(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	p1, p2 := NewRandomGenotype(6, r), NewRandomGenotype(6, r)
+	c1, c2 := BlendCrossover{}.Crossover(p1, p2, r)
+	for i := range p1 {
+		lo, hi := p1[i].weight, p2[i].weight
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for _, c := range []Genotype{c1, c2} {
+			if c[i].weight < lo || c[i].weight > hi {
+				t.Errorf("Expected weight between %v and %v with no alpha, got %v", lo, hi, c[i].weight)
+			}
+		}
+	}
+}
+
+func TestBoundedWeights(t *testing.T) {
+	for _, x := range []float64{-2.5, -1e300, 1e300, 3.25, math.Inf(1), math.Inf(-1), math.NaN()} {
+		if w := bounded(x); w < 0 || w > 1 {
+			t.Errorf("Expected %v to be bounded within [0, 1], got %v", x, w)
+		}
+	}
+	if w := bounded(1.25); w != 0.75 {
+		t.Errorf("Expected 1.25 to be reflected to 0.75, got %v", w)
+	}
+	r := rand.New(rand.NewSource(1))
+	p1, p2 := NewRandomGenotype(6, r), NewRandomGenotype(6, r)
+	//A tiny Eta spreads children over extreme betas
+	sbx := SBXCrossover{Eta: 1e-9}
+	for k := 0; k < 100; k++ {
+		c1, c2 := sbx.Crossover(p1, p2, r)
+		for i := range c1 {
+			if w1, w2 := c1[i].weight, c2[i].weight; w1 < 0 || w1 > 1 || w2 < 0 || w2 > 1 {
+				t.Fatalf("Expected weights within [0, 1], got %v and %v", w1, w2)
+			}
+		}
+	}
+}
+
+func sortedWeights(g Genotype) []float64 {
+	weights := make([]float64, len(g))
+	for i, wj := range g {
+		weights[i] = float64(wj.weight)
+	}
+	sort.Float64s(weights)
+	return weights
+}
+
+func TestOrderCrossover(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for k := 0; k < 20; k++ {
+		p1, p2 := NewRandomGenotype(7, r), NewRandomGenotype(7, r)
+		c1, _ := OrderCrossover(p1, p2, r)
+		//a permutation of the parent's ranks
+		w1, w := sortedWeights(p1), sortedWeights(c1)
+		for i := range w {
+			if w[i] != w1[i] {
+				t.Fatalf("Expected the child to reuse its parent's weights, got %v and %v", w, w1)
+			}
+		}
+		//genes ranked as in p2 keep their relative order
+		var fromP2 []int
+		for _, gene := range ranking(c1) {
+			if c1[gene].config != p1[gene].config || c1[gene].weight != p1[gene].weight {
+				fromP2 = append(fromP2, gene)
+			}
+		}
+		rank2 := make([]int, len(p2))
+		for rank, gene := range ranking(p2) {
+			rank2[gene] = rank
+		}
+		for i := 1; i < len(fromP2); i++ {
+			if rank2[fromP2[i-1]] > rank2[fromP2[i]] {
+				t.Fatalf("Expected genes filled in from p2 to keep their order")
+			}
+		}
+	}
+}
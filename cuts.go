@@ -0,0 +1,102 @@
+package guillotine
+
+//A guillotine cut, a straight line across the whole piece being cut,
+//from (X1, Y1) to (X2, Y2) in Drawing coordinates.
+//Stage counts orientation changes: consecutive cuts with the same
+//orientation belong to the same stage, first stage is 1.
+type Cut struct {
+	X1, Y1, X2, Y2 uint
+	Stage          uint
+}
+
+type cutter struct {
+	lt   *LayoutTree
+	cuts []Cut
+}
+
+//Cut lines needed to extract every board from a sheet, including
+//the trims that separate boards from waste. The sheet is anchored at
+//the layout's top left corner and must be at least as big as the
+//layout; pass a zero Board to use the layout's own size.
+func (lt *LayoutTree) Cuts(sheet Board) []Cut {
+	root := lt.Areas[len(lt.Areas)-1]
+	if sheet.Width == 0 || sheet.Height == 0 {
+		sheet = root
+	}
+	c := &cutter{lt: lt, cuts: make([]Cut, 0, 2*len(lt.Spec.Boards))}
+	c.cut(2*lt.Nboards-2, Rect{0, 0, sheet.Width, sheet.Height}, 0, HORIZONTAL)
+	return c.cuts
+}
+
+//Stage for a new cut, given the cut that produced the piece being cut.
+func cutStage(d Direction, stage uint, parent Direction) uint {
+	if stage == 0 || d != parent {
+		return stage + 1
+	}
+	return stage
+}
+
+func (c *cutter) add(x1, y1, x2, y2 uint, d Direction, stage uint, parent Direction) uint {
+	stage = cutStage(d, stage, parent)
+	c.cuts = append(c.cuts, Cut{x1, y1, x2, y2, stage})
+	return stage
+}
+
+//Cuts the subtree i, given as a mixed index, out of region. Cut
+//directions follow the stacking convention, a VERTICAL cut is a
+//horizontal line separating pieces stacked on top of each other.
+func (c *cutter) cut(i uint16, region Rect, stage uint, parent Direction) {
+	lt := c.lt
+	board := lt.getBoard(i, lt.Spec.Boards, lt.Areas)
+	if board.Width < region.Width {
+		x := region.X + board.Width
+		stage = c.add(x, region.Y, x, region.Y+region.Height, HORIZONTAL, stage, parent)
+		parent = HORIZONTAL
+		region.Width = board.Width
+	}
+	if board.Height < region.Height {
+		y := region.Y + board.Height
+		stage = c.add(region.X, y, region.X+region.Width, y, VERTICAL, stage, parent)
+		parent = VERTICAL
+		region.Height = board.Height
+	}
+	if i < lt.Nboards {
+		return
+	}
+	node := lt.Stacks[i-lt.Nboards]
+	left := lt.getBoard(node.Left, lt.Spec.Boards, lt.Areas)
+	first, second := region, region
+	if node.Direction == VERTICAL {
+		y := region.Y + left.Height
+		stage = c.add(region.X, y, region.X+region.Width, y, VERTICAL, stage, parent)
+		first.Height = left.Height
+		second.Y, second.Height = y, region.Height-left.Height
+	} else {
+		x := region.X + left.Width
+		stage = c.add(x, region.Y, x, region.Y+region.Height, HORIZONTAL, stage, parent)
+		first.Width = left.Width
+		second.X, second.Width = x, region.Width-left.Width
+	}
+	c.cut(node.Left, first, stage, node.Direction)
+	c.cut(node.Right, second, stage, node.Direction)
+}
+
+//Amount of cuts needed to extract the boards from the layout's own
+//bounding box.
+func (lt *LayoutTree) CutCount() uint {
+	return uint(len(lt.Cuts(Board{})))
+}
+
+//Amount of cutting stages, see Cut.
+func (lt *LayoutTree) Stages() uint {
+	var stages uint
+	for _, c := range lt.Cuts(Board{}) {
+		if c.Stage > stages {
+			stages = c.Stage
+		}
+	}
+	return stages
+}
+
+var _ Fitness = (*LayoutTree).CutCount
+var _ Fitness = (*LayoutTree).Stages
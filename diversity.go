@@ -0,0 +1,276 @@
+package guillotine
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+//Distance between genotypes of the same spec, in [0, 1]: the mean
+//weight difference and the fraction of different configs, averaged.
+func GenotypeDistance(a, b Genotype) float64 {
+	if len(a) == 0 {
+		return 0
+	}
+	var weights float64
+	var configs int
+	for k := range a {
+		weights += math.Abs(float64(a[k].weight) - float64(b[k].weight))
+		if a[k].config != b[k].config {
+			configs++
+		}
+	}
+	n := float64(len(a))
+	return (weights/n + float64(configs)/n) / 2
+}
+
+//Distance between layouts of the same spec, in [0, 1]: the fraction of
+//stacks, in canonical form, that aren't shared by both layouts.
+//Equivalent layouts are at distance 0, see LayoutTree.Canonical.
+func LayoutDistance(a, b *LayoutTree) float64 {
+	sa, sb := a.canonicalStacks(), b.canonicalStacks()
+	var shared, total int
+	for s, na := range sa {
+		nb := sb[s]
+		if nb < na {
+			shared += nb
+			total += na
+		} else {
+			shared += na
+			total += nb
+		}
+	}
+	for s, nb := range sb {
+		if _, ok := sa[s]; !ok {
+			total += nb
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 1 - float64(shared)/float64(total)
+}
+
+//Canonical forms of every stack in the layout, nested stacks in the
+//same direction being part of the enclosing one, by multiplicity.
+func (lt *LayoutTree) canonicalStacks() map[string]int {
+	stacks := make(map[string]int, lt.Nboards)
+	lt.collectStacks(2*lt.Nboards-2, stacks)
+	return stacks
+}
+
+func (lt *LayoutTree) collectStacks(i uint16, stacks map[string]int) {
+	if i < lt.Nboards {
+		return
+	}
+	direction := lt.Stacks[i-lt.Nboards].Direction
+	stacks[lt.canonical(i)]++
+	var operands func(i uint16)
+	operands = func(i uint16) {
+		if i < lt.Nboards {
+			return
+		}
+		if node := lt.Stacks[i-lt.Nboards]; node.Direction == direction {
+			operands(node.Left)
+			operands(node.Right)
+		} else {
+			lt.collectStacks(i, stacks)
+		}
+	}
+	node := lt.Stacks[i-lt.Nboards]
+	operands(node.Left)
+	operands(node.Right)
+}
+
+//Mean distance between every pair of individuals, 0 for less than two.
+func meanPairwise(n int, distance func(i, j int) float64) float64 {
+	if n < 2 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			sum += distance(i, j)
+		}
+	}
+	return sum / float64(n*(n-1)/2)
+}
+
+//Mean GenotypeDistance across the population, quadratic on its size.
+func GenotypeDiversity(pop Population) float64 {
+	return meanPairwise(len(pop), func(i, j int) float64 {
+		return GenotypeDistance(pop[i], pop[j])
+	})
+}
+
+//Mean LayoutDistance across the population, quadratic on its size.
+func LayoutDiversity(spec *CutSpec, pop Population) float64 {
+	layouts := make([]*LayoutTree, len(pop))
+	for i, g := range pop {
+		layouts[i] = GetPhenotype(spec, g)
+	}
+	return meanPairwise(len(pop), func(i, j int) float64 {
+		return LayoutDistance(layouts[i], layouts[j])
+	})
+}
+
+//Fitness sharing: individuals with close genotypes split their
+//fitness, so selection doesn't crowd a single niche. Each fitness is
+//scaled by the individual's niche count, the sum of
+//1 - (d/Radius)^Alpha over the individuals at a GenotypeDistance d
+//below Radius, itself included. Fitnesses are minimized, so sharing
+//makes them larger.
+type FitnessSharing struct {
+	Radius float64
+	Alpha  float64
+}
+
+//Sharing between two genotypes, 0 when they're Radius apart or more.
+func (fs *FitnessSharing) sh(a, b Genotype) float64 {
+	if d := GenotypeDistance(a, b); d < fs.Radius {
+		return 1 - math.Pow(d/fs.Radius, fs.Alpha)
+	}
+	return 0
+}
+
+//Niche count of every individual, what its fitness is multiplied by.
+func (fs *FitnessSharing) niches(pop Population) []float64 {
+	n := len(pop)
+	niche := make([]float64, n)
+	for i := 0; i < n; i++ {
+		niche[i]++
+		for j := i + 1; j < n; j++ {
+			sh := fs.sh(pop[i], pop[j])
+			niche[i] += sh
+			niche[j] += sh
+		}
+	}
+	return niche
+}
+
+//Population ranked by shared fitness, for selection only, along with
+//the index in rp of each of its individuals.
+func (fs *FitnessSharing) share(rp *RankedPopulation) (*RankedPopulation, []int) {
+	return sharedView(rp, fs.niches(rp.Pop))
+}
+
+//Like share, given the niche count of every individual in rp.
+func sharedView(rp *RankedPopulation, niche []float64) (*RankedPopulation, []int) {
+	n := len(rp.Pop)
+	shared := &RankedPopulation{Pop: make(Population, n), Fitnesses: make([]uint, n)}
+	copy(shared.Pop, rp.Pop)
+	index := make([]int, n)
+	for i, f := range rp.Fitnesses {
+		shared.Fitnesses[i] = uint(float64(f) * niche[i])
+		index[i] = i
+	}
+	sort.Stable(indexedPopulation{shared, index})
+	return shared, index
+}
+
+//Updates the niche counts of pop when g replaces the individual at v,
+//in linear time, returning the niche count of g. The count at v is
+//left as it was.
+func (fs *FitnessSharing) replace(pop Population, niche []float64, v int, g Genotype) float64 {
+	count := 1.0
+	for i := range pop {
+		if i == v {
+			continue
+		}
+		sh := fs.sh(g, pop[i])
+		niche[i] += sh - fs.sh(pop[v], pop[i])
+		count += sh
+	}
+	return count
+}
+
+//Sorts a ranked population along with the original index of each
+//individual.
+type indexedPopulation struct {
+	*RankedPopulation
+	index []int
+}
+
+func (ip indexedPopulation) Swap(i, j int) {
+	ip.RankedPopulation.Swap(i, j)
+	ip.index[i], ip.index[j] = ip.index[j], ip.index[i]
+}
+
+//Replaces part of the population with random genotypes whenever its
+//GenotypeDiversity falls below Threshold.
+type RandomImmigrants struct {
+	Threshold float64
+	//Fraction of the population replaced, elites are always kept
+	Fraction float64
+}
+
+//Replaces the last individuals of pop, bred from parents, when the
+//parents aren't diverse enough. The first keep individuals are left
+//alone. Returns how many were replaced.
+func (ri *RandomImmigrants) immigrate(parents *RankedPopulation, pop Population, keep uint,
+	nboards uint16, r *rand.Rand) int {
+
+	if GenotypeDiversity(parents.Pop) >= ri.Threshold {
+		return 0
+	}
+	n := int(ri.Fraction * float64(len(pop)))
+	if max := len(pop) - int(keep); n > max {
+		n = max
+	}
+	for i := len(pop) - n; i < len(pop); i++ {
+		pop[i] = NewRandomGenotype(nboards, r)
+	}
+	return n
+}
+
+//Deterministic crowding: parents are paired at random and each child
+//competes with the parent it's closest to, replacing it only if it's
+//at least as fit. Elites are implicitly kept, as no individual is ever
+//replaced by a less fit one. Returns the next population and the
+//fraction of children that beat their parent.
+func (ga *GeneticAlgorithm) crowd(rp *RankedPopulation) (*RankedPopulation, float64) {
+	n := len(rp.Pop)
+	order := ga.R.Perm(n)
+	pairs := n / 2
+	children := make(Population, 2*pairs)
+	e := ga.engine()
+	for k := 0; k < pairs; k++ {
+		p1, p2 := rp.Pop[order[2*k]], rp.Pop[order[2*k+1]]
+		children[2*k], children[2*k+1] = e.Breed(p1, p2)
+	}
+	if ga.Immigrants != nil {
+		ga.Immigrants.immigrate(rp, children, 0, uint16(len(ga.Spec.Boards)), ga.R)
+	}
+	fitness := e.Fitnesses(children)
+	next := &RankedPopulation{Pop: make(Population, n), Fitnesses: make([]uint, n)}
+	var successes int
+	for k := 0; k < pairs; k++ {
+		p1, p2 := order[2*k], order[2*k+1]
+		c1, c2 := 2*k, 2*k+1
+		d := GenotypeDistance
+		if d(rp.Pop[p1], children[c1])+d(rp.Pop[p2], children[c2]) >
+			d(rp.Pop[p1], children[c2])+d(rp.Pop[p2], children[c1]) {
+			c1, c2 = c2, c1
+		}
+		for _, match := range [][2]int{{p1, c1}, {p2, c2}} {
+			parent, child := match[0], match[1]
+			if fitness[child] <= rp.Fitnesses[parent] {
+				next.Pop[parent], next.Fitnesses[parent] = children[child], fitness[child]
+				if fitness[child] < rp.Fitnesses[parent] {
+					successes++
+				}
+			} else {
+				next.Pop[parent], next.Fitnesses[parent] = rp.Pop[parent], rp.Fitnesses[parent]
+			}
+		}
+	}
+	if n%2 != 0 {
+		last := order[n-1]
+		next.Pop[last], next.Fitnesses[last] = rp.Pop[last], rp.Fitnesses[last]
+	}
+	sort.Sort(next)
+	if len(children) == 0 {
+		return next, 0
+	}
+	return next, float64(successes) / float64(len(children))
+}
@@ -0,0 +1,118 @@
+package guillotine
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGenotypeDistance(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a, b := NewRandomGenotype(8, r), NewRandomGenotype(8, r)
+	if d := GenotypeDistance(a, a); d != 0 {
+		t.Errorf("Expected a genotype at distance 0 of itself, got %v", d)
+	}
+	if d, e := GenotypeDistance(a, b), GenotypeDistance(b, a); d <= 0 || d > 1 || d != e {
+		t.Errorf("Expected a symmetric distance in (0, 1], got %v and %v", d, e)
+	}
+	pop := Population{a, a, a}
+	if d := GenotypeDiversity(pop); d != 0 {
+		t.Errorf("Expected clones to have no diversity, got %v", d)
+	}
+	pop[2] = b
+	if d := GenotypeDiversity(pop); d <= 0 {
+		t.Errorf("Expected some diversity, got %v", d)
+	}
+}
+
+func TestLayoutDistance(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	spec := NewRandomSpec(8, 40, 50, r, false)
+	a := GetPhenotype(spec, NewRandomGenotype(8, r))
+	b := GetPhenotype(spec, NewRandomGenotype(8, r))
+	if d := LayoutDistance(a, a); d != 0 {
+		t.Errorf("Expected a layout at distance 0 of itself, got %v", d)
+	}
+	if a.Canonical() != b.Canonical() && LayoutDistance(a, b) == 0 {
+		t.Errorf("Expected different layouts at a positive distance")
+	}
+	if d, e := LayoutDistance(a, b), LayoutDistance(b, a); d < 0 || d > 1 || d != e {
+		t.Errorf("Expected a symmetric distance in [0, 1], got %v and %v", d, e)
+	}
+}
+
+func TestFitnessSharing(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	clone, other := NewRandomGenotype(8, r), NewRandomGenotype(8, r)
+	rp := &RankedPopulation{Pop: Population{clone, clone, clone, other}, Fitnesses: []uint{10, 10, 10, 20}}
+	shared, index := (&FitnessSharing{Radius: 0.01, Alpha: 1}).share(rp)
+	if shared.Pop[0][0] != other[0] || shared.Fitnesses[0] != 20 || shared.Fitnesses[3] != 30 {
+		t.Errorf("Expected clones to share their fitness, got %v", shared.Fitnesses)
+	}
+	if index[0] != 3 || index[3] != 2 {
+		t.Errorf("Expected the index of each individual in the ranked population, got %v", index)
+	}
+	if rp.Fitnesses[0] != 10 {
+		t.Errorf("Expected the ranked population to be left alone")
+	}
+}
+
+func TestFitnessSharingReplace(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	fs := &FitnessSharing{Radius: 0.4, Alpha: 1}
+	rp := &RankedPopulation{Pop: NewRandomPopulation(6, 10, r), Fitnesses: make([]uint, 10)}
+	for i := range rp.Fitnesses {
+		rp.Fitnesses[i] = uint(10 * i)
+	}
+	niche := fs.niches(rp.Pop)
+	for k, v := range []int{9, 0, 4, 7} {
+		g := rp.Pop[(v+3)%10].copy()
+		g[0].weight = r.Float32()
+		count := fs.replace(rp.Pop, niche, v, g)
+		moveNiche(niche, v, rp.Replace(v, g, uint(25*k)), count)
+		for i, expected := range fs.niches(rp.Pop) {
+			if math.Abs(niche[i]-expected) > 1e-9 {
+				t.Fatalf("Expected niche count %v at %d after %d replacements, got %v", expected, i, k+1, niche[i])
+			}
+		}
+	}
+}
+
+func TestRandomImmigrants(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	clone := NewRandomGenotype(8, r)
+	parents := &RankedPopulation{Pop: Population{clone, clone, clone, clone}, Fitnesses: []uint{1, 1, 1, 1}}
+	pop := Population{clone, clone, clone, clone}
+	ri := &RandomImmigrants{Threshold: 0.1, Fraction: 0.9}
+	if n := ri.immigrate(parents, pop, 1, 8, r); n != 3 || pop[0][0] != clone[0] {
+		t.Errorf("Expected all but the elite to be replaced, got %d", n)
+	}
+	if d := GenotypeDiversity(pop); d <= 0.1 {
+		t.Errorf("Expected immigrants to restore diversity, got %v", d)
+	}
+	if n := ri.immigrate(&RankedPopulation{Pop: pop}, pop, 1, 8, r); n != 0 {
+		t.Errorf("Expected no immigrants on a diverse population, got %d", n)
+	}
+}
+
+func TestCrowding(t *testing.T) {
+	ga := testGA(rand.New(rand.NewSource(1)), 8)
+	ga.Crowding = true
+	ga.Generations = 30
+	ga.Immigrants = &RandomImmigrants{Threshold: 0.05, Fraction: 0.2}
+	var previous uint
+	ga.Observer = func(p *Progress) {
+		best := p.Population.Fitnesses[0]
+		if previous != 0 && best > previous {
+			t.Errorf("Expected crowding to never lose the best, got %d after %d", best, previous)
+		}
+		previous = best
+		if n := len(p.Population.Pop); n != 20 {
+			t.Errorf("Expected the population size to be kept, got %d", n)
+		}
+	}
+	if _, err := ga.RunContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,218 @@
+package guillotine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//Sheet corner placed at the DXF origin
+type Corner uint8
+
+const (
+	BottomLeft Corner = iota
+	TopLeft
+	BottomRight
+	TopRight
+)
+
+var cornerNames = map[string]Corner{
+	"bottomleft":  BottomLeft,
+	"topleft":     TopLeft,
+	"bottomright": BottomRight,
+	"topright":    TopRight,
+}
+
+func ParseCorner(name string) (Corner, error) {
+	if c, ok := cornerNames[strings.ToLower(name)]; ok {
+		return c, nil
+	}
+	return BottomLeft, fmt.Errorf("unknown corner <%s>", name)
+}
+
+//Drawing units, values are the DXF $INSUNITS codes. Coordinates are
+//always in board units. $INSUNITS was only defined on AutoCAD 2000
+//files, so readers of the R12 files WriteDXF produces may ignore it,
+//and units are advisory.
+type DXFUnits uint8
+
+const (
+	Unitless    DXFUnits = 0
+	Inches      DXFUnits = 1
+	Feet        DXFUnits = 2
+	Millimeters DXFUnits = 4
+	Centimeters DXFUnits = 5
+	Meters      DXFUnits = 6
+)
+
+var unitNames = map[string]DXFUnits{
+	"none": Unitless,
+	"in":   Inches,
+	"ft":   Feet,
+	"mm":   Millimeters,
+	"cm":   Centimeters,
+	"m":    Meters,
+}
+
+func ParseDXFUnits(name string) (DXFUnits, error) {
+	if u, ok := unitNames[strings.ToLower(name)]; ok {
+		return u, nil
+	}
+	return Unitless, fmt.Errorf("unknown units <%s>", name)
+}
+
+type DXFOptions struct {
+	Origin Corner
+	Units  DXFUnits
+	//Sheet size, a zero Board means the layout's own size
+	Sheet Board
+}
+
+const (
+	sheetLayer = "SHEET"
+	partsLayer = "PARTS"
+	labelLayer = "LABELS"
+	cutsLayer  = "CUTS"
+)
+
+type dxfWriter struct {
+	w             *bufio.Writer
+	width, height uint
+	origin        Corner
+}
+
+//Writes the layout as an R12 DXF drawing, with the sheet outline,
+//part rectangles, part labels and guillotine cut lines on their
+//own layers. The header includes opts.Units, see DXFUnits.
+func WriteDXF(w io.Writer, lt *LayoutTree, opts DXFOptions) error {
+	drawing := NewDrawer(lt).Draw()
+	sheet := opts.Sheet
+	if sheet.Width == 0 || sheet.Height == 0 {
+		sheet = Board{drawing.Sheet.Width, drawing.Sheet.Height}
+	}
+	dw := &dxfWriter{w: bufio.NewWriter(w), width: sheet.Width, height: sheet.Height, origin: opts.Origin}
+
+	dw.section("HEADER")
+	dw.pair(9, "$ACADVER")
+	dw.pair(1, "AC1009")
+	dw.pair(9, "$INSUNITS")
+	dw.pair(70, fmt.Sprint(uint8(opts.Units)))
+	dw.endSection()
+
+	dw.section("TABLES")
+	dw.pair(0, "TABLE")
+	dw.pair(2, "LAYER")
+	dw.pair(70, "4")
+	for i, layer := range []string{sheetLayer, partsLayer, labelLayer, cutsLayer} {
+		dw.pair(0, "LAYER")
+		dw.pair(2, layer)
+		dw.pair(70, "0")
+		dw.pair(62, fmt.Sprint(i+1))
+		dw.pair(6, "CONTINUOUS")
+	}
+	dw.pair(0, "ENDTAB")
+	dw.endSection()
+
+	dw.section("ENTITIES")
+	dw.rect(sheetLayer, Rect{0, 0, sheet.Width, sheet.Height})
+	for i, box := range drawing.Boxes {
+		dw.rect(partsLayer, box)
+		dw.label(i, box)
+	}
+	for _, cut := range lt.Cuts(sheet) {
+		dw.line(cutsLayer, cut.X1, cut.Y1, cut.X2, cut.Y2)
+	}
+	dw.endSection()
+	dw.pair(0, "EOF")
+	return dw.w.Flush()
+}
+
+func (dw *dxfWriter) pair(code int, value string) {
+	fmt.Fprintf(dw.w, "%3d\n%s\n", code, value)
+}
+
+//Plain decimal notation, DXF readers don't take exponents or -0
+func num(v float64) string {
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func (dw *dxfWriter) section(name string) {
+	dw.pair(0, "SECTION")
+	dw.pair(2, name)
+}
+
+func (dw *dxfWriter) endSection() {
+	dw.pair(0, "ENDSEC")
+}
+
+//Maps Drawing coordinates (y grows downwards, origin at the top left
+//corner) to DXF coordinates (y grows upwards) with the origin at the
+//configured sheet corner.
+func (dw *dxfWriter) point(x, y float64) (float64, float64) {
+	w, h := float64(dw.width), float64(dw.height)
+	switch dw.origin {
+	case TopLeft:
+		return x, -y
+	case BottomRight:
+		return x - w, h - y
+	case TopRight:
+		return x - w, -y
+	default:
+		return x, h - y
+	}
+}
+
+func (dw *dxfWriter) coords(code int, x, y float64) {
+	x, y = dw.point(x, y)
+	dw.pair(code, num(x))
+	dw.pair(code+10, num(y))
+	dw.pair(code+20, "0")
+}
+
+func (dw *dxfWriter) line(layer string, x1, y1, x2, y2 uint) {
+	dw.pair(0, "LINE")
+	dw.pair(8, layer)
+	dw.coords(10, float64(x1), float64(y1))
+	dw.coords(11, float64(x2), float64(y2))
+}
+
+func (dw *dxfWriter) rect(layer string, r Rect) {
+	x0, y0 := float64(r.X), float64(r.Y)
+	x1, y1 := float64(r.X+r.Width), float64(r.Y+r.Height)
+	dw.pair(0, "POLYLINE")
+	dw.pair(8, layer)
+	dw.pair(66, "1")
+	dw.pair(10, "0")
+	dw.pair(20, "0")
+	dw.pair(30, "0")
+	dw.pair(70, "1") //closed
+	for _, p := range [][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+		dw.pair(0, "VERTEX")
+		dw.pair(8, layer)
+		dw.coords(10, p[0], p[1])
+	}
+	dw.pair(0, "SEQEND")
+	dw.pair(8, layer)
+}
+
+//Part index and size, centered on the part.
+func (dw *dxfWriter) label(i int, r Rect) {
+	size := r.Width
+	if r.Height < size {
+		size = r.Height
+	}
+	cx, cy := float64(r.X)+float64(r.Width)/2, float64(r.Y)+float64(r.Height)/2
+	dw.pair(0, "TEXT")
+	dw.pair(8, labelLayer)
+	dw.coords(10, cx, cy)
+	dw.pair(40, num(float64(size)/5))
+	dw.pair(1, fmt.Sprintf("%d (%dx%d)", i, r.Width, r.Height))
+	dw.pair(72, "1") //centered
+	dw.coords(11, cx, cy)
+	dw.pair(73, "2") //middle
+}
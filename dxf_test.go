@@ -0,0 +1,65 @@
+package guillotine
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//Group code and value pairs of a DXF file.
+func dxfPairs(t *testing.T, data []byte) [][2]string {
+	var pairs [][2]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		code := strings.TrimSpace(scanner.Text())
+		if _, err := strconv.Atoi(code); err != nil {
+			t.Fatalf("Expected a group code, got %q", code)
+		}
+		if !scanner.Scan() {
+			t.Fatalf("Expected a value after group code %s", code)
+		}
+		pairs = append(pairs, [2]string{code, scanner.Text()})
+	}
+	return pairs
+}
+
+func TestWriteDXF(t *testing.T) {
+	lt := randomLayout(rand.New(rand.NewSource(1)), 8)
+	var b bytes.Buffer
+	if err := WriteDXF(&b, lt, DXFOptions{Units: Millimeters}); err != nil {
+		t.Fatal(err)
+	}
+	pairs := dxfPairs(t, b.Bytes())
+	entities := make(map[string]int)
+	var sections []string
+	var units string
+	for i, p := range pairs {
+		if p[0] == "0" {
+			entities[p[1]]++
+		}
+		if p == [2]string{"0", "SECTION"} {
+			sections = append(sections, pairs[i+1][1])
+		}
+		if p == [2]string{"9", "$INSUNITS"} {
+			units = pairs[i+1][1]
+		}
+	}
+	if strings.Join(sections, " ") != "HEADER TABLES ENTITIES" || entities["ENDSEC"] != 3 {
+		t.Errorf("Expected header, tables and entities sections, got %v", sections)
+	}
+	if units != "4" {
+		t.Errorf("Expected millimeters, got units %q", units)
+	}
+	if entities["POLYLINE"] != 9 || entities["SEQEND"] != 9 || entities["VERTEX"] != 36 {
+		t.Errorf("Expected the sheet and 8 parts as closed polylines, got %v", entities)
+	}
+	if entities["TEXT"] != 8 || entities["LINE"] != len(lt.Cuts(Board{})) {
+		t.Errorf("Expected a label per part and a line per cut, got %v", entities)
+	}
+	if last := pairs[len(pairs)-1]; last != [2]string{"0", "EOF"} {
+		t.Errorf("Expected the file to end with EOF, got %v", last)
+	}
+}
@@ -0,0 +1,94 @@
+package guillotine
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+//Position of the (i, j) join in genotypes built by NewGenotype
+func joinIndex(n, i, j uint16) int {
+	ii, nn := int(i), int(n)
+	return ii*(2*nn-ii-1)/2 + int(j) - ii - 1
+}
+
+//Genotype that GetPhenotype decodes back into the given layout.
+//Joins used by the layout get the lowest weights, in the order the
+//layout nodes were created, and the remaining ones get random higher
+//weights and configs.
+//A genotype join always refers to the lower board index first. When
+//every board on the left side of a stack has a higher index than the
+//boards on the right side, the decoded stack comes out with its sides
+//swapped, a mirrored but equivalent layout (see LayoutTree.Canonical).
+func EncodeLayout(lt *LayoutTree, r *rand.Rand) (Genotype, error) {
+	n := lt.Nboards
+	if n < 2 || int(n) != len(lt.Spec.Boards) || lt.NextNode != n-1 {
+		return nil, fmt.Errorf("layout is not complete")
+	}
+	g := NewRandomGenotype(n, r)
+	for k := range g {
+		g[k].weight = 0.5 + g[k].weight/2
+	}
+	//lowest and highest board index under each node
+	low := make([]uint16, n-1)
+	high := make([]uint16, n-1)
+	bounds := func(i uint16) (uint16, uint16) {
+		if i < n {
+			return i, i
+		}
+		return low[i-n], high[i-n]
+	}
+	for k := uint16(0); k < n-1; k++ {
+		node := lt.Stacks[k]
+		if node.Left >= n+k || node.Right >= n+k {
+			return nil, fmt.Errorf("layout node %d refers to a later node", k)
+		}
+		lLow, lHigh := bounds(node.Left)
+		rLow, rHigh := bounds(node.Right)
+		low[k], high[k] = lLow, lHigh
+		if rLow < low[k] {
+			low[k] = rLow
+		}
+		if rHigh > high[k] {
+			high[k] = rHigh
+		}
+		//leaves must represent themselves, so their rotation is set
+		i, j := lLow, rHigh
+		if i > j {
+			i, j = rLow, lHigh
+		}
+		config := JOIN.direct(node.Direction)
+		if lt.pickRotation(i) {
+			config = config.irotated()
+		}
+		if lt.pickRotation(j) {
+			config = config.jrotated()
+		}
+		wj := &g[joinIndex(n, i, j)]
+		wj.config = config
+		wj.weight = float32(k) / float32(2*n)
+	}
+	return g, nil
+}
+
+//Rotation config that makes take() pick board i as rotated in lt,
+//it may be flipped to comply with MaxWidth.
+func (lt *LayoutTree) pickRotation(i uint16) bool {
+	rot := lt.Picks[i].Rot
+	if lt.rotationOnMaxWidth(i, rot) != rot {
+		return !rot
+	}
+	return rot
+}
+
+//Random population holding copies of the given seeds first.
+func NewSeededPopulation(seeds []Genotype, nboards uint16, size uint, r *rand.Rand) Population {
+	pop := make([]Genotype, size)
+	for i := range pop {
+		if i < len(seeds) {
+			pop[i] = seeds[i].copy()
+		} else {
+			pop[i] = NewRandomGenotype(nboards, r)
+		}
+	}
+	return pop
+}
@@ -0,0 +1,27 @@
+package guillotine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeLayout(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, limitWidth := range []bool{false, true} {
+		spec := NewRandomSpec(15, 60, 80, r, limitWidth)
+		for n := 0; n < 50; n++ {
+			lt := GetPhenotype(spec, NewRandomGenotype(15, r))
+			g, err := EncodeLayout(lt, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := GetPhenotype(spec, g)
+			if c1, c2 := lt.Canonical(), decoded.Canonical(); c1 != c2 {
+				t.Fatalf("Expected encoded layout [%v], got [%v]", c1, c2)
+			}
+			if lt.Area() != decoded.Area() {
+				t.Fatalf("Expected encoded layout area %v, got %v", lt.Area(), decoded.Area())
+			}
+		}
+	}
+}
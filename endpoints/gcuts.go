@@ -2,12 +2,15 @@ package gcuts
 
 //	"appengine/datastore"
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/crhym3/go-endpoints/endpoints"
 	"github.com/rdarder/guillotine"
 	"math"
 	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 )
 const (
@@ -28,6 +31,13 @@ type CutSpec struct {
 	Orders   []BoardOrder            `json:"orders" endpoints:"req"`
 	MaxWidth uint                    `json:"maxWidth"`
 	Hints    *GeneticAlgorithmParams `json:"hints" endpoints:"req"`
+	Dxf      *DxfParams              `json:"dxf"`
+}
+
+// When present on a CutSpec, the results include a DXF drawing
+type DxfParams struct {
+	Origin string `json:"origin" endpoints:"d=bottomleft"`
+	Units  string `json:"units" endpoints:"d=mm"`
 }
 
 type Placement struct {
@@ -48,24 +58,93 @@ type CutResults struct {
 	Waste        uint             `json:"waste"`
 	WastePercent float64          `json:"wastePercent"`
 	RunDetails   RunDetails       `json:"runDetails" endpoints:"required"`
+	Dxf          string           `json:"dxf"`
+	// Only for multi objective runs, the main layout is the first one
+	Front []ParetoLayout `json:"front"`
+}
+
+// A layout on the Pareto front of a multi objective run
+type ParetoLayout struct {
+	// In the same order as the requested objectives
+	Objectives []uint           `json:"objectives"`
+	Placements []BoardPlacement `json:"boardPlacements"`
+	Sheet      Board            `json:"sheet"`
 }
 type RunDetails struct {
 	Generations uint
+	StopReason  string
+	// Seed hint that, with Generations as the stop condition, reproduces the run
+	Seed int64
+	// Summary of every generation, only when the Stats hint is set
+	Stats []guillotine.GenerationStats
+	// Fitness cache lookups
+	CacheHits, CacheMisses uint
+	// Mutation means along the run, only for AdaptiveMutation
+	Mutation []guillotine.MutationStep
 }
 
 type GeneticAlgorithmParams struct {
-	ConfigMutateMean   float64 `endpoints:"d=5"`
-	WeightMutateMean   float64 `endpoints:"d=5"`
+	ConfigMutateMean float64 `endpoints:"d=5"`
+	WeightMutateMean float64 `endpoints:"d=5"`
+	// One of uniform, onepoint, twopoint, blend, arithmetic, sbx or order
 	Crossover          string  `endpoints:"d=twopoint"`
+	BlendAlpha         float64 `endpoints:"d=0.5"`
+	SBXEta             float64 `endpoints:"d=2"`
 	TournamentSize     uint    `endpoints:"d=8"`
 	FittestProbability float32 `endpoints:"d=0.7"`
 	Population         uint    `endpoints:"d=50"`
 	Generations        uint    `endpoints:"d=100"`
 	EliteSize          uint    `endpoints:"d=5"`
+	// Stop after this many generations without improvement, 0 disables it.
+	Stagnation uint `endpoints:"d=0"`
+	// Additional mutations, 0 disables them. Means are per individual.
+	GaussianMutateMean  float64 `endpoints:"d=0"`
+	GaussianSigma       float64 `endpoints:"d=0.1"`
+	SwapMutateMean      float64 `endpoints:"d=0"`
+	InversionMutateMean float64 `endpoints:"d=0"`
+	PromoteMutateMean   float64 `endpoints:"d=0"`
+	// Tune the mutation means during the run, starting from the given ones.
+	AdaptiveMutation bool `endpoints:"d=false"`
+	// One of tournament, roulette, rank, sus or truncation
+	Selection          string  `endpoints:"d=tournament"`
+	RankPressure       float64 `endpoints:"d=1.7"`
+	TruncationFraction float64 `endpoints:"d=0.3"`
+	// Include per generation statistics in the run details.
+	Stats bool `endpoints:"d=false"`
+	// Objectives for a multi objective run: area, height, cuts or stages.
+	Objectives []string
+	// One of ga, brkga or annealing. Annealing runs Generations
+	// temperature levels of AnnealingMoves neighbours, split across
+	// Restarts.
+	Engine         string  `endpoints:"d=ga"`
+	Cooling        string  `endpoints:"d=geometric"`
+	CoolingRate    float64 `endpoints:"d=0.95"`
+	AnnealingMoves uint    `endpoints:"d=50"`
+	Restarts       uint    `endpoints:"d=0"`
+	// Fractions of the brkga population kept as elite and replaced by
+	// random layouts, and the odds of inheriting from the elite parent.
+	BRKGAElite   float64 `endpoints:"d=0.2"`
+	BRKGAMutants float64 `endpoints:"d=0.15"`
+	BRKGARho     float64 `endpoints:"d=0.7"`
+	// Seed for the run's random numbers, 0 picks a new one.
+	Seed int64 `endpoints:"d=0"`
 }
 
 type Guillotine struct {
-	r *rand.Rand
+	// r is shared by concurrent requests
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// A new seed for a request that didn't ask for one
+func (gn *Guillotine) newSeed() int64 {
+	gn.mu.Lock()
+	defer gn.mu.Unlock()
+	for {
+		if seed := gn.r.Int63(); seed != 0 {
+			return seed
+		}
+	}
 }
 
 func NormUint(mean float64, stddev float64, r *rand.Rand) uint {
@@ -83,11 +162,24 @@ var defaultHints GeneticAlgorithmParams = GeneticAlgorithmParams{
 	ConfigMutateMean:   5,
 	WeightMutateMean:   5,
 	Crossover:          "twopoint",
+	BlendAlpha:         0.5,
+	SBXEta:             2,
 	TournamentSize:     8,
 	FittestProbability: 0.7,
 	Population:         50,
 	Generations:        200,
 	EliteSize:          5,
+	GaussianSigma:      0.1,
+	Selection:          "tournament",
+	RankPressure:       1.7,
+	TruncationFraction: 0.3,
+	Engine:             "ga",
+	Cooling:            "geometric",
+	CoolingRate:        0.95,
+	AnnealingMoves:     50,
+	BRKGAElite:         0.2,
+	BRKGAMutants:       0.15,
+	BRKGARho:           0.7,
 }
 
 func GetGeneticAlgorithm(spec *guillotine.CutSpec, params GeneticAlgorithmParams,
@@ -104,27 +196,78 @@ func GetGeneticAlgorithm(spec *guillotine.CutSpec, params GeneticAlgorithmParams
 		breeder = guillotine.OnePointCrossover
 	case "twopoint":
 		breeder = guillotine.TwoPointCrossover
+	case "blend":
+		if params.BlendAlpha < 0 {
+			return nil, paramError("BlendAlpha", params.BlendAlpha)
+		}
+		breeder = guillotine.BlendCrossover{Alpha: params.BlendAlpha}.Crossover
+	case "arithmetic":
+		breeder = guillotine.ArithmeticCrossover
+	case "sbx":
+		if params.SBXEta <= 0 {
+			return nil, paramError("SBXEta", params.SBXEta)
+		}
+		breeder = guillotine.SBXCrossover{Eta: params.SBXEta}.Crossover
+	case "order":
+		breeder = guillotine.OrderCrossover
 	default:
 		return nil, paramError("Crossover", params.Crossover)
 	}
 
 	var evaluator guillotine.Fitness
+	var lowerBound uint
 	if spec.MaxWidth != 0 {
 		evaluator = (*guillotine.LayoutTree).Height
+		lowerBound = spec.HeightLowerBound()
 	} else {
 		evaluator = (*guillotine.LayoutTree).Area
+		lowerBound = spec.AreaLowerBound()
+	}
+
+	var selector guillotine.SelectorBuilder
+	switch params.Selection {
+	case "tournament", "":
+		if tsize := params.TournamentSize; tsize < 1 || tsize > params.Population {
+			return nil, paramError("TournamentSize", tsize)
+		} else if psel := params.FittestProbability; psel <= 0 || psel > 1 {
+			return nil, paramError("fittestProbability", psel)
+		}
+		selector = guillotine.NewTournamentSelectorBuilder(
+			int(params.TournamentSize), params.FittestProbability, r, true)
+	case "roulette":
+		selector = guillotine.NewRouletteSelectorBuilder(r, true)
+	case "rank":
+		if params.RankPressure < 1 || params.RankPressure > 2 {
+			return nil, paramError("RankPressure", params.RankPressure)
+		}
+		selector = guillotine.NewLinearRankSelectorBuilder(params.RankPressure, r, true)
+	case "sus":
+		selector = guillotine.NewSUSSelectorBuilder(r, true)
+	case "truncation":
+		if params.TruncationFraction <= 0 || params.TruncationFraction > 1 {
+			return nil, paramError("TruncationFraction", params.TruncationFraction)
+		}
+		selector = guillotine.NewTruncationSelectorBuilder(params.TruncationFraction, r, true)
+	default:
+		return nil, paramError("Selection", params.Selection)
 	}
 
 	if cMean := params.ConfigMutateMean; cMean < 0 {
 		return nil, paramError("ConfigMutateMean", cMean)
 	} else if wMean := params.WeightMutateMean; wMean < 0 {
 		return nil, paramError("ConfigMutateMean", wMean)
+	} else if gMean := params.GaussianMutateMean; gMean < 0 {
+		return nil, paramError("GaussianMutateMean", gMean)
+	} else if sigma := params.GaussianSigma; sigma < 0 {
+		return nil, paramError("GaussianSigma", sigma)
+	} else if sMean := params.SwapMutateMean; sMean < 0 {
+		return nil, paramError("SwapMutateMean", sMean)
+	} else if iMean := params.InversionMutateMean; iMean < 0 {
+		return nil, paramError("InversionMutateMean", iMean)
+	} else if pMean := params.PromoteMutateMean; pMean < 0 {
+		return nil, paramError("PromoteMutateMean", pMean)
 	} else if population := params.Population; population < 1 || population > 1000 {
 		return nil, paramError("Population", population)
-	} else if tsize := params.TournamentSize; tsize < 1 || tsize > population {
-		return nil, paramError("TournamentSize", tsize)
-	} else if psel := params.FittestProbability; psel < 0 || psel >= 1 {
-		return nil, paramError("fittestProbability", psel)
 	} else if eliteSize := params.EliteSize; eliteSize < 0 || eliteSize > population {
 		return nil, paramError("EliteSize", eliteSize)
 	} else if generations := params.Generations; generations < 1 || generations > 10000 {
@@ -135,8 +278,14 @@ func GetGeneticAlgorithm(spec *guillotine.CutSpec, params GeneticAlgorithmParams
 		//1MM is > 30boards * 1000 generations
 		return nil, fmt.Errorf("Resource limits: Try lowering population or board count")
 	} else {
-		//
-		return &guillotine.GeneticAlgorithm{
+		stop := []guillotine.StopCondition{
+			guillotine.MaxGenerations(generations),
+			guillotine.LowerBound(lowerBound),
+		}
+		if params.Stagnation > 0 {
+			stop = append(stop, guillotine.Stagnation(params.Stagnation))
+		}
+		ga := &guillotine.GeneticAlgorithm{
 			Spec:      spec,
 			Evaluator: evaluator,
 			Mutator: guillotine.CompoundWeightConfigMutator{
@@ -149,14 +298,38 @@ func GetGeneticAlgorithm(spec *guillotine.CutSpec, params GeneticAlgorithmParams
 					StdDev: params.ConfigMutateMean / 5,
 				},
 			}.Mutate,
-			Breeder: breeder,
-			SelectorBuilder: guillotine.NewTournamentSelectorBuilder(
-				int(tsize), psel, r, true),
-			R:              r,
-			EliteSize:      eliteSize,
-			PopulationSize: population,
-			Generations:    generations,
-		}, nil
+			Breeder:         breeder,
+			SelectorBuilder: selector,
+			R:               r,
+			EliteSize:       eliteSize,
+			PopulationSize:  population,
+			Generations:     generations,
+			Stop:            guillotine.AnyOf(stop...),
+			// elites and unchanged children are looked up instead of evaluated again
+			Cache: guillotine.NewFitnessCache(4 * int(population)),
+		}
+		if params.AdaptiveMutation {
+			mutator := guillotine.NewAdaptiveMutator(params.WeightMutateMean, params.ConfigMutateMean)
+			ga.Mutator, ga.Adapter = mutator.Mutate, mutator
+		}
+		mutators := guillotine.CompoundMutator{ga.Mutator}
+		if gMean > 0 {
+			mutators = append(mutators, guillotine.GaussianWeightMutator{
+				Mean: gMean, StdDev: gMean / 5, Sigma: sigma}.Mutate)
+		}
+		if sMean > 0 {
+			mutators = append(mutators, guillotine.SwapMutator{Mean: sMean, StdDev: sMean / 5}.Mutate)
+		}
+		if iMean > 0 {
+			mutators = append(mutators, guillotine.InversionMutator{Mean: iMean, StdDev: iMean / 5}.Mutate)
+		}
+		if pMean > 0 {
+			mutators = append(mutators, guillotine.PromoteMutator{Mean: pMean, StdDev: pMean / 5}.Mutate)
+		}
+		if len(mutators) > 1 {
+			ga.Mutator = mutators.Mutate
+		}
+		return ga, nil
 	}
 }
 
@@ -205,29 +378,180 @@ func GetPlacements(lt *guillotine.LayoutTree) (sheet Board, bps []BoardPlacement
 	return sheet, bps
 }
 
+func GetDXF(lt *guillotine.LayoutTree, sheet Board, params *DxfParams) (string, error) {
+	origin, err := guillotine.ParseCorner(params.Origin)
+	if err != nil {
+		return "", paramError("Dxf origin", params.Origin)
+	}
+	units, err := guillotine.ParseDXFUnits(params.Units)
+	if err != nil {
+		return "", paramError("Dxf units", params.Units)
+	}
+	var b bytes.Buffer
+	err = guillotine.WriteDXF(&b, lt, guillotine.DXFOptions{
+		Origin: origin,
+		Units:  units,
+		Sheet:  guillotine.Board{Width: sheet.Width, Height: sheet.Height},
+	})
+	return b.String(), err
+}
+
+// Runs a multi objective search with the settings of ga, returning the
+// Pareto front sorted by the first objective, and its first layout.
+func GetParetoFront(ctx context.Context, ga *guillotine.GeneticAlgorithm, objectives []string,
+	details *RunDetails) ([]ParetoLayout, *guillotine.LayoutTree, error) {
+
+	if ga.PopulationSize < 2 {
+		return nil, nil, paramError("Population", ga.PopulationSize)
+	}
+	nsga := &guillotine.NSGA2{
+		Spec:           ga.Spec,
+		Mutator:        ga.Mutator,
+		Breeder:        ga.Breeder,
+		R:              ga.R,
+		PopulationSize: ga.PopulationSize,
+		Generations:    ga.Generations,
+		Workers:        ga.Workers,
+		Observer:       ga.Observer,
+	}
+	for _, name := range objectives {
+		objective, err := guillotine.ParseObjective(name)
+		if err != nil {
+			return nil, nil, paramError("Objectives", name)
+		}
+		nsga.Objectives = append(nsga.Objectives, objective)
+	}
+	// stop working on the request once the client goes away
+	result, err := nsga.TimeBoundedRunContext(ctx, gaTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	front := make([]ParetoLayout, len(result.Front))
+	for i, solution := range result.Front {
+		sheet, placements := GetPlacements(solution.Layout)
+		front[i] = ParetoLayout{solution.Values, placements, sheet}
+	}
+	details.Generations = result.Generations
+	details.StopReason = result.Reason
+	return front, result.Front[0].Layout, nil
+}
+
+// A simulated annealing run with the spec, mutations and stop
+// conditions of ga.
+func GetAnnealing(ga *guillotine.GeneticAlgorithm, params GeneticAlgorithmParams) (*guillotine.SimulatedAnnealing, error) {
+	cooling, err := guillotine.ParseCooling(params.Cooling, params.CoolingRate)
+	if err != nil {
+		return nil, paramError("Cooling", params.Cooling)
+	}
+	if moves := params.AnnealingMoves; moves < 1 || moves > 1000 {
+		return nil, paramError("AnnealingMoves", moves)
+	} else if restarts := params.Restarts; restarts >= params.Generations {
+		return nil, paramError("Restarts", restarts)
+	}
+	return &guillotine.SimulatedAnnealing{
+		Spec:      ga.Spec,
+		Evaluator: ga.Evaluator,
+		Mutator:   ga.Mutator,
+		Cooling:   cooling,
+		Moves:     params.AnnealingMoves,
+		Levels:    params.Generations / (params.Restarts + 1),
+		Restarts:  params.Restarts,
+		R:         ga.R,
+		Source:    ga.Source,
+		Stop:      ga.Stop,
+		Cache:     ga.Cache,
+	}, nil
+}
+
 func (gn *Guillotine) Cut(r *http.Request, msg *CutSpec, resp *CutResults) error {
 	if msg.Hints == nil {
 		msg.Hints = &defaultHints
 	}
 
+	seed := msg.Hints.Seed
+	if seed == 0 {
+		seed = gn.newSeed()
+	}
+	// every request draws from its own source, so runs don't interfere
+	// with each other and can be repeated from the returned seed
+	src := guillotine.NewSource(seed)
 	if cutSpec, err := CutSpecFromMessage(msg); err != nil {
 		return err
-	} else if ga, err := GetGeneticAlgorithm(cutSpec, *msg.Hints, gn.r); err != nil {
+	} else if ga, err := GetGeneticAlgorithm(cutSpec, *msg.Hints, rand.New(src)); err != nil {
 		return err
 	} else {
-		generations, layout := ga.TimeBoundedRun(gaTimeout)
+		ga.Source = src
+		var layout *guillotine.LayoutTree
+		var collector *guillotine.StatsCollector
+		if msg.Hints.Stats {
+			collector = guillotine.NewStatsCollector(cutSpec)
+			ga.Observer = collector.Observe
+		}
+		if len(msg.Hints.Objectives) > 0 {
+			front, best, err := GetParetoFront(r.Context(), ga, msg.Hints.Objectives, &resp.RunDetails)
+			if err != nil {
+				return err
+			}
+			resp.Front, layout = front, best
+		} else {
+			run := ga.TimeBoundedRunContext
+			switch msg.Hints.Engine {
+			case "ga", "":
+			case "brkga":
+				if elite := msg.Hints.BRKGAElite; elite <= 0 || elite >= 1 {
+					return paramError("BRKGAElite", elite)
+				} else if mutants := msg.Hints.BRKGAMutants; mutants < 0 || elite+mutants >= 1 {
+					return paramError("BRKGAMutants", mutants)
+				} else if rho := msg.Hints.BRKGARho; rho < 0.5 || rho > 1 {
+					return paramError("BRKGARho", rho)
+				} else {
+					ga.BRKGA = &guillotine.BRKGA{Elite: elite, Mutants: mutants, Rho: rho}
+				}
+			case "annealing":
+				sa, err := GetAnnealing(ga, *msg.Hints)
+				if err != nil {
+					return err
+				}
+				sa.Observer = ga.Observer
+				run = sa.TimeBoundedRunContext
+			default:
+				return paramError("Engine", msg.Hints.Engine)
+			}
+			// stop working on the request once the client goes away
+			result, err := run(r.Context(), gaTimeout)
+			if err != nil {
+				return err
+			}
+			layout = result.Layout
+			resp.RunDetails.Generations = result.Generations
+			resp.RunDetails.StopReason = result.Reason
+			stats := ga.Cache.Stats()
+			resp.RunDetails.CacheHits, resp.RunDetails.CacheMisses = stats.Hits, stats.Misses
+		}
+		if collector != nil {
+			resp.RunDetails.Stats = collector.Generations
+		}
 		sheet, placements := GetPlacements(layout)
 		resp.Waste = sheet.Width*sheet.Height - cutSpec.TotalArea
 		resp.WastePercent = 100 * float64(resp.Waste) / float64(cutSpec.TotalArea)
 		resp.Placements = placements
 		resp.Sheet = sheet
-		resp.RunDetails.Generations = generations
+		resp.RunDetails.Seed = seed
+		if mutator, ok := ga.Adapter.(*guillotine.AdaptiveMutator); ok {
+			resp.RunDetails.Mutation = mutator.Trajectory
+		}
+		if msg.Dxf != nil {
+			if resp.Dxf, err = GetDXF(layout, sheet, msg.Dxf); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
 func (gn *Guillotine) RandomSpec(r *http.Request, p *endpoints.VoidMessage, spec *CutSpec) error{
-
+	gn.mu.Lock()
+	defer gn.mu.Unlock()
 	spec.MaxWidth = NormUint(200, 40, gn.r)
 	for i := NormUint(5, 3, gn.r) + 2; i > 0; i-- {
 		order := BoardOrder{
@@ -249,7 +573,7 @@ func (gn *Guillotine) DefaultHints(r *http.Request, p *endpoints.VoidMessage, hi
 
 func init() {
 	r := rand.New(rand.NewSource(time.Now().Unix()))
-	guillotine := &Guillotine{r}
+	guillotine := &Guillotine{r: r}
 
 	api, err := endpoints.RegisterService(guillotine,
 		"guillotine", "v1", "Guillotine Cuts API", true)
@@ -0,0 +1,297 @@
+//Generic genetic algorithm engine: ranking, elitism, selection,
+//breeding, stop conditions and progress reports for genotypes of any
+//type G, decoding to phenotypes of type P with a fitness of type F.
+//Fitnesses are minimized.
+package evolve
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Crossover[G any] func(p1, p2 G, r *rand.Rand) (c1, c2 G)
+
+type Mutator[G any] func(g G, r *rand.Rand)
+
+//Snapshot of a run, taken after evaluating each generation.
+type Progress[G, P any, F Number] struct {
+	//Amount of generations evaluated so far, including the initial one
+	Generation uint
+	Best       F
+	Mean       float64
+	//Best phenotype of the current generation
+	Phenotype  P
+	Population *Ranked[G, F]
+	Elapsed    time.Duration
+	//Amount of genotypes evaluated so far
+	Evaluations uint
+	//Best fitness and phenotype across all generations
+	BestSoFar     F
+	BestPhenotype P
+	//Generation in which BestSoFar was found, 0 before the first one
+	Improved uint
+}
+
+//Called by the Engine on each generation, must not modify the
+//population.
+type Observer[G, P any, F Number] func(p *Progress[G, P, F])
+
+type Result[G, P any, F Number] struct {
+	Generations uint
+	Evaluations uint
+	//Best phenotype found, along with its fitness
+	Phenotype  P
+	Fitness    F
+	Population *Ranked[G, F]
+	//Why the run ended, see StopCondition
+	Reason string
+}
+
+type Engine[G, P any, F Number] struct {
+	//Random genotype for the initial population
+	Random func(r *rand.Rand) G
+	//Phenotype of a genotype, only decoded for the best of each generation
+	Decode func(g G) P
+	//Must be safe for concurrent use when there are Workers
+	Fitness         func(g G) F
+	Mutator         Mutator[G]
+	Breeder         Crossover[G]
+	SelectorBuilder SelectorBuilder[G, F]
+	//Every random choice of a run is drawn from R, so runs with equally
+	//seeded R are identical (unless they stop on wall clock time).
+	R              *rand.Rand
+	EliteSize      uint
+	PopulationSize uint
+	Generations    uint
+	//Amount of goroutines evaluating the population, 0 or 1 evaluates
+	//sequentially.
+	Workers int
+	//Optional, genotypes included as they are in the initial population
+	Seeds []G
+	//Optional, replaces Fitness on Evaluate for rankings that depend on
+	//the whole population: the individuals of pop to keep, sorted, along
+	//with their fitness.
+	Ranking func(pop []G) *Ranked[G, F]
+	//Optional, the population parents are selected from instead of the
+	//ranked one, sorted the same way, along with the index in the
+	//ranked population of each of its individuals.
+	SelectionView func(rp *Ranked[G, F]) (*Ranked[G, F], []int)
+	//Optional, called by Next with the generation it bred out of rp,
+	//before it's evaluated, and the indices in rp of the parents of each
+	//individual, elites being their own parents. It may replace any
+	//individual but the elites.
+	OnChildren func(rp *Ranked[G, F], next []G, parents [][2]int)
+	//Optional, replaces Next: the next generation after p, evaluated
+	//and sorted.
+	NextGeneration func(p *Progress[G, P, F]) *Ranked[G, F]
+	//Optional, notified of the progress of every generation
+	Observer Observer[G, P, F]
+	//When to end a run, defaults to MaxGenerations(Generations)
+	Stop StopCondition[G, P, F]
+}
+
+//Calls f on consecutive chunks of [0, n), one goroutine per chunk
+//when there's more than one worker.
+func ParallelRange(n, workers int, f func(start, end int)) {
+	if workers <= 1 {
+		f(0, n)
+		return
+	}
+	var wg sync.WaitGroup
+	chunk := (n + workers - 1) / workers
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			f(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+//Fitness of every genotype, in the same order. Every fitness only
+//depends on its own genotype, so splitting the work across workers
+//yields the same result as a sequential evaluation.
+func (e *Engine[G, P, F]) Fitnesses(pop []G) []F {
+	fitness := make([]F, len(pop))
+	ParallelRange(len(pop), e.Workers, func(start, end int) {
+		for i := start; i < end; i++ {
+			fitness[i] = e.Fitness(pop[i])
+		}
+	})
+	return fitness
+}
+
+//Ranked population out of pop, through Ranking when there's one.
+func (e *Engine[G, P, F]) Evaluate(pop []G) *Ranked[G, F] {
+	if e.Ranking != nil {
+		return e.Ranking(pop)
+	}
+	rp := &Ranked[G, F]{pop, e.Fitnesses(pop)}
+	sort.Sort(rp)
+	return rp
+}
+
+//Two mutated children of p1 and p2.
+func (e *Engine[G, P, F]) Breed(p1, p2 G) (c1, c2 G) {
+	c1, c2 = e.Breeder(p1, p2, e.R)
+	e.Mutator(c1, e.R)
+	e.Mutator(c2, e.R)
+	return c1, c2
+}
+
+//Picks parents out of rp, through the SelectionView when there's one,
+//returning their index in rp.
+func (e *Engine[G, P, F]) Select(rp *Ranked[G, F]) func() int {
+	if e.SelectionView == nil {
+		return e.SelectorBuilder(rp).NextIndex
+	}
+	view, index := e.SelectionView(rp)
+	selector := e.SelectorBuilder(view)
+	return func() int {
+		return index[selector.NextIndex()]
+	}
+}
+
+//Next generation of rp, unevaluated: its EliteSize fittest individuals
+//followed by the children of selected parents.
+func (e *Engine[G, P, F]) Next(rp *Ranked[G, F]) []G {
+	selector := e.Select(rp)
+	psize := uint(len(rp.Pop))
+	next := make([]G, psize)
+	parents := make([][2]int, psize)
+	copy(next[:e.EliteSize], rp.Pop[:e.EliteSize])
+	for i := range parents[:e.EliteSize] {
+		parents[i] = [2]int{i, i}
+	}
+	for i := e.EliteSize; i < psize; i++ {
+		p1, p2 := selector(), selector()
+		c1, c2 := e.Breed(rp.Pop[p1], rp.Pop[p2])
+		next[i], parents[i] = c1, [2]int{p1, p2}
+		if i < psize-1 {
+			i++
+			next[i], parents[i] = c2, [2]int{p1, p2}
+		}
+	}
+	if e.OnChildren != nil {
+		e.OnChildren(rp, next, parents)
+	}
+	return next
+}
+
+//Updates the progress summary to a new ranked population.
+func (e *Engine[G, P, F]) Rank(p *Progress[G, P, F], rp *Ranked[G, F]) {
+	var sum float64
+	for _, f := range rp.Fitnesses {
+		sum += float64(f)
+	}
+	p.Population = rp
+	p.Best = rp.Fitnesses[0]
+	p.Mean = sum / float64(len(rp.Fitnesses))
+	p.Phenotype = e.Decode(rp.Pop[0])
+	if p.Improved == 0 || p.Best < p.BestSoFar {
+		p.BestSoFar, p.BestPhenotype, p.Improved = p.Best, p.Phenotype, p.Generation
+	}
+}
+
+//Progress after evaluating rp, prev is the progress of the
+//previous generation, nil for the initial one.
+func (e *Engine[G, P, F]) progress(prev *Progress[G, P, F], rp *Ranked[G, F], start time.Time) *Progress[G, P, F] {
+	p := &Progress[G, P, F]{
+		Generation:  1,
+		Elapsed:     time.Since(start),
+		Evaluations: uint(len(rp.Pop)),
+	}
+	if prev != nil {
+		p.Generation += prev.Generation
+		p.Evaluations += prev.Evaluations
+		p.BestSoFar, p.BestPhenotype, p.Improved = prev.BestSoFar, prev.BestPhenotype, prev.Improved
+	}
+	e.Rank(p, rp)
+	if e.Observer != nil {
+		e.Observer(p)
+	}
+	return p
+}
+
+//Evaluates the Seeds along with random genotypes, the first generation
+//of a run.
+func (e *Engine[G, P, F]) First(start time.Time) *Progress[G, P, F] {
+	pop := make([]G, e.PopulationSize)
+	for i := range pop {
+		if i < len(e.Seeds) {
+			pop[i] = e.Seeds[i]
+		} else {
+			pop[i] = e.Random(e.R)
+		}
+	}
+	return e.progress(nil, e.Evaluate(pop), start)
+}
+
+//Breeds and evaluates the generation after p.
+func (e *Engine[G, P, F]) Step(p *Progress[G, P, F], start time.Time) *Progress[G, P, F] {
+	var rp *Ranked[G, F]
+	if e.NextGeneration != nil {
+		rp = e.NextGeneration(p)
+	} else {
+		rp = e.Evaluate(e.Next(p.Population))
+	}
+	return e.progress(p, rp, start)
+}
+
+func (e *Engine[G, P, F]) stopCondition() StopCondition[G, P, F] {
+	if e.Stop != nil {
+		return e.Stop
+	}
+	return MaxGenerations[G, P, F](e.Generations)
+}
+
+//Evolves from the generation in p until stop says so, or until ctx is
+//done. On cancellation, the result holds the best phenotype found so
+//far along with the context's error.
+func (e *Engine[G, P, F]) Evolve(ctx context.Context, p *Progress[G, P, F], start time.Time,
+	stop StopCondition[G, P, F]) (*Result[G, P, F], error) {
+
+	var err error
+	var reason string
+	for {
+		var done bool
+		if done, reason = stop(p); done {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			reason = err.Error()
+			break
+		}
+		p = e.Step(p, start)
+	}
+	return &Result[G, P, F]{
+		Generations: p.Generation,
+		Evaluations: p.Evaluations,
+		Phenotype:   p.BestPhenotype,
+		Fitness:     p.BestSoFar,
+		Population:  p.Population,
+		Reason:      reason,
+	}, err
+}
+
+//Evolves a random population until e.Stop, or e.Generations if
+//there's no Stop condition.
+func (e *Engine[G, P, F]) RunContext(ctx context.Context) (*Result[G, P, F], error) {
+	start := time.Now()
+	return e.Evolve(ctx, e.First(start), start, e.stopCondition())
+}
+
+//Like RunContext, but also stops when the next generation is expected
+//to end past the time limit.
+func (e *Engine[G, P, F]) TimeBoundedRunContext(ctx context.Context, limit time.Duration) (*Result[G, P, F], error) {
+	start := time.Now()
+	return e.Evolve(ctx, e.First(start), start, AnyOf(e.stopCondition(), WallClock[G, P, F](limit)))
+}
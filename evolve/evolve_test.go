@@ -0,0 +1,179 @@
+package evolve
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+//Bit strings scored by their amount of unset bits.
+type bits []bool
+
+func oneMax(seed int64) *Engine[bits, int, int] {
+	r := rand.New(rand.NewSource(seed))
+	return &Engine[bits, int, int]{
+		Random: func(r *rand.Rand) bits {
+			b := make(bits, 32)
+			for i := range b {
+				b[i] = r.Intn(2) == 0
+			}
+			return b
+		},
+		Decode: func(b bits) int {
+			var ones int
+			for _, bit := range b {
+				if bit {
+					ones++
+				}
+			}
+			return ones
+		},
+		Fitness: func(b bits) int {
+			var zeros int
+			for _, bit := range b {
+				if !bit {
+					zeros++
+				}
+			}
+			return zeros
+		},
+		Mutator: func(b bits, r *rand.Rand) {
+			i := r.Intn(len(b))
+			b[i] = !b[i]
+		},
+		Breeder: func(p1, p2 bits, r *rand.Rand) (c1, c2 bits) {
+			cut := r.Intn(len(p1))
+			c1 = append(append(bits{}, p1[:cut]...), p2[cut:]...)
+			c2 = append(append(bits{}, p2[:cut]...), p1[cut:]...)
+			return
+		},
+		SelectorBuilder: NewTournamentSelectorBuilder[bits, int](3, 0.8, r, true),
+		R:               r,
+		EliteSize:       2,
+		PopulationSize:  30,
+		Generations:     60,
+	}
+}
+
+func TestEngine(t *testing.T) {
+	e := oneMax(1)
+	var previous int
+	e.Observer = func(p *Progress[bits, int, int]) {
+		if p.Generation > 1 && p.BestSoFar > previous {
+			t.Errorf("Expected the best fitness to never get worse, got %d after %d", p.BestSoFar, previous)
+		}
+		previous = p.BestSoFar
+	}
+	e.Stop = AnyOf(MaxGenerations[bits, int, int](60), TargetFitness[bits, int](0))
+	result, err := e.RunContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Fitness > 2 || result.Phenotype != 32-result.Fitness {
+		t.Errorf("Expected nearly every bit set, got %d ones", result.Phenotype)
+	}
+	if result.Evaluations != 30*result.Generations {
+		t.Errorf("Expected 30 evaluations per generation, got %d", result.Evaluations)
+	}
+}
+
+func TestEngineReproducible(t *testing.T) {
+	a, _ := oneMax(3).RunContext(context.Background())
+	b, _ := oneMax(3).RunContext(context.Background())
+	for i := range a.Population.Fitnesses {
+		if a.Population.Fitnesses[i] != b.Population.Fitnesses[i] {
+			t.Fatalf("Expected equally seeded runs to match, got %v and %v",
+				a.Population.Fitnesses, b.Population.Fitnesses)
+		}
+	}
+}
+
+func TestEngineNextGeneration(t *testing.T) {
+	e := oneMax(2)
+	var calls int
+	e.NextGeneration = func(p *Progress[bits, int, int]) *Ranked[bits, int] {
+		calls++
+		return e.Evaluate(e.Next(p.Population))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.Observer = func(p *Progress[bits, int, int]) {
+		if p.Generation == 5 {
+			cancel()
+		}
+	}
+	result, err := e.RunContext(ctx)
+	if err == nil || result.Generations != 5 || calls != 4 {
+		t.Errorf("Expected a cancelled run after 5 generations, got %d (%v)", result.Generations, err)
+	}
+}
+
+func TestEngineHooks(t *testing.T) {
+	e := oneMax(4)
+	e.Generations = 3
+	//Only the fittest individual is ever selected
+	e.SelectionView = func(rp *Ranked[bits, int]) (*Ranked[bits, int], []int) {
+		view := &Ranked[bits, int]{Pop: make([]bits, 3), Fitnesses: make([]int, 3)}
+		for i := range view.Pop {
+			view.Pop[i], view.Fitnesses[i] = rp.Pop[0], rp.Fitnesses[0]
+		}
+		return view, []int{0, 0, 0}
+	}
+	var calls int
+	e.OnChildren = func(rp *Ranked[bits, int], next []bits, parents [][2]int) {
+		calls++
+		for i, p := range parents {
+			if i < int(e.EliteSize) && p != [2]int{i, i} || i >= int(e.EliteSize) && p != [2]int{0, 0} {
+				t.Fatalf("Expected elites to be their own parents and the fittest to parent every child, got %v", parents)
+			}
+		}
+		next[len(next)-1] = make(bits, 32)
+	}
+	result, _ := e.RunContext(context.Background())
+	if calls != 2 || result.Population.Fitnesses[len(result.Population.Fitnesses)-1] != 32 {
+		t.Errorf("Expected 2 generations with a replaced child, got %d and %v", calls, result.Population.Fitnesses)
+	}
+}
+
+func TestRanked(t *testing.T) {
+	rp := &Ranked[string, float64]{
+		Pop:       []string{"a", "b", "a", "c"},
+		Fitnesses: []float64{1, 2, 3, 4},
+	}
+	distinct := Distinct(rp, func(s string) string { return s })
+	if len(distinct.Pop) != 3 || distinct.Fitnesses[1] != 2 {
+		t.Errorf("Expected the best of each key, got %v", distinct.Fitnesses)
+	}
+	rp.Replace(3, "d", 0)
+	if rp.Pop[0] != "d" || rp.Fitnesses[3] != 3 {
+		t.Errorf("Expected d to be the fittest, got %v", rp.Pop)
+	}
+	s := Summarize(rp)
+	if s.Best != 0 || s.Worst != 3 || s.Mean != 1.5 {
+		t.Errorf("Expected best 0, worst 3 and mean 1.5, got %+v", s)
+	}
+}
+
+func TestEngineRank(t *testing.T) {
+	e := oneMax(5)
+	e.Generations = 3
+	var calls int
+	//Ranked by ones instead, keeping half the population
+	e.Ranking = func(pop []bits) *Ranked[bits, int] {
+		calls++
+		rp := &Ranked[bits, int]{Pop: pop, Fitnesses: make([]int, len(pop))}
+		for i, b := range pop {
+			rp.Fitnesses[i] = e.Decode(b)
+		}
+		sort.Sort(rp)
+		rp.Pop, rp.Fitnesses = rp.Pop[:len(pop)/2], rp.Fitnesses[:len(pop)/2]
+		return rp
+	}
+	e.NextGeneration = func(p *Progress[bits, int, int]) *Ranked[bits, int] {
+		return e.Evaluate(append(append([]bits{}, p.Population.Pop...), e.Next(p.Population)...))
+	}
+	result, _ := e.RunContext(context.Background())
+	if calls != 3 || len(result.Population.Pop) != 15 || result.Evaluations != 45 {
+		t.Errorf("Expected 3 rankings of 15 survivors, got %d of %d", calls, len(result.Population.Pop))
+	}
+}
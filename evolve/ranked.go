@@ -0,0 +1,81 @@
+package evolve
+
+import (
+	"math"
+	"sort"
+)
+
+//Fitness values. Engines minimize them, selectors can do either.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+//Population along with the fitness of each individual, sorted by
+//ascending fitness once evaluated.
+type Ranked[G any, F Number] struct {
+	Pop       []G
+	Fitnesses []F
+}
+
+func (rp *Ranked[G, F]) Less(i, j int) bool {
+	return rp.Fitnesses[i] < rp.Fitnesses[j]
+}
+func (rp *Ranked[G, F]) Swap(i, j int) {
+	rp.Pop[i], rp.Pop[j] = rp.Pop[j], rp.Pop[i]
+	rp.Fitnesses[i], rp.Fitnesses[j] = rp.Fitnesses[j], rp.Fitnesses[i]
+}
+func (rp *Ranked[G, F]) Len() int { return len(rp.Pop) }
+
+//Replaces the individual at v by g, moving individuals around so rp
+//stays sorted. Returns the index g ends up at, every individual in
+//between shifting one place towards v.
+func (rp *Ranked[G, F]) Replace(v int, g G, fitness F) int {
+	i := sort.Search(len(rp.Fitnesses), func(i int) bool { return rp.Fitnesses[i] > fitness })
+	if i > v {
+		i--
+		copy(rp.Pop[v:i], rp.Pop[v+1:i+1])
+		copy(rp.Fitnesses[v:i], rp.Fitnesses[v+1:i+1])
+	} else {
+		copy(rp.Pop[i+1:v+1], rp.Pop[i:v])
+		copy(rp.Fitnesses[i+1:v+1], rp.Fitnesses[i:v])
+	}
+	rp.Pop[i], rp.Fitnesses[i] = g, fitness
+	return i
+}
+
+//Keeps the first, best ranked, individual of every key.
+func Distinct[G any, F Number, K comparable](rp *Ranked[G, F], key func(g G) K) *Ranked[G, F] {
+	seen := make(map[K]bool, len(rp.Pop))
+	distinct := &Ranked[G, F]{}
+	for i, g := range rp.Pop {
+		if k := key(g); !seen[k] {
+			seen[k] = true
+			distinct.Pop = append(distinct.Pop, g)
+			distinct.Fitnesses = append(distinct.Fitnesses, rp.Fitnesses[i])
+		}
+	}
+	return distinct
+}
+
+//Fitness distribution of a sorted population.
+type Summary[F Number] struct {
+	Best, Worst  F
+	Mean, StdDev float64
+}
+
+func Summarize[G any, F Number](rp *Ranked[G, F]) Summary[F] {
+	n := len(rp.Fitnesses)
+	var sum float64
+	for _, f := range rp.Fitnesses {
+		sum += float64(f)
+	}
+	mean := sum / float64(n)
+	var variance float64
+	for _, f := range rp.Fitnesses {
+		d := float64(f) - mean
+		variance += d * d
+	}
+	return Summary[F]{rp.Fitnesses[0], rp.Fitnesses[n-1], mean, math.Sqrt(variance / float64(n))}
+}
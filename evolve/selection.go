@@ -0,0 +1,286 @@
+package evolve
+
+import (
+	"math/rand"
+	"sort"
+)
+
+//Picks parents out of a ranked population, one at a time. Builders get
+//the population sorted by ascending fitness.
+type Selector[G any] interface {
+	Next() G
+	//Like Next, but returns the index of the parent in the population
+	NextIndex() int
+}
+type SelectorBuilder[G any, F Number] func(rp *Ranked[G, F]) Selector[G]
+
+//Index of the individual with the given rank, 0 being the fittest.
+//Ranked populations are sorted by ascending fitness, so the fittest
+//comes first when minimizing and last when maximizing.
+func rankIndex(rank, n int, min bool) int {
+	if min {
+		return rank
+	}
+	return n - 1 - rank
+}
+
+type TournamentSelector[G any, F Number] struct {
+	size int
+	buf  fitnessPositions[F]
+	p    float32
+	rp   *Ranked[G, F]
+	r    *rand.Rand
+	min  bool
+}
+
+//Tournaments of size candidates, drawn with replacement, where the
+//fittest wins with probability p, see winnerRank. size must be between
+//1 and the population size, and p in (0, 1].
+func NewTournamentSelectorBuilder[G any, F Number](size int, p float32, r *rand.Rand, min bool) SelectorBuilder[G, F] {
+	if size < 1 {
+		panic("Tournament size must be at least 1")
+	}
+	if p <= 0 || p > 1 {
+		panic("Tournament probability must be in (0, 1]")
+	}
+	return func(rp *Ranked[G, F]) Selector[G] {
+		if size > len(rp.Pop) {
+			panic("Tournament size must not exceed the population size")
+		}
+		return &TournamentSelector[G, F]{
+			size: size,
+			buf:  make(fitnessPositions[F], size),
+			p:    p,
+			r:    r,
+			rp:   rp,
+			min:  min,
+		}
+	}
+}
+
+type fitnessPosition[F Number] struct {
+	i       int
+	fitness F
+}
+
+type fitnessPositions[F Number] []fitnessPosition[F]
+
+func (fps fitnessPositions[F]) Len() int           { return len(fps) }
+func (fps fitnessPositions[F]) Less(i, j int) bool { return fps[i].fitness < fps[j].fitness }
+func (fps fitnessPositions[F]) Swap(i, j int)      { fps[i], fps[j] = fps[j], fps[i] }
+
+//The fittest candidate wins with probability p, the second one with
+//p*(1-p), and so on. p must be positive.
+func (ts *TournamentSelector[G, F]) winnerRank() int {
+	//This could be faster if modelled with a
+	//negative binomial distribution generator
+	for {
+		for i := 0; i < ts.size; i++ {
+			if ts.r.Float32() < ts.p {
+				return i
+			}
+		}
+	}
+}
+
+func (ts *TournamentSelector[G, F]) Next() G {
+	return ts.rp.Pop[ts.NextIndex()]
+}
+
+func (ts *TournamentSelector[G, F]) NextIndex() int {
+	fps := ts.buf
+	for i := 0; i < ts.size; i++ {
+		ri := ts.r.Intn(len(ts.rp.Fitnesses))
+		fps[i].i = ri
+		fps[i].fitness = ts.rp.Fitnesses[ri]
+	}
+	var winnerIndex int
+	winnerRank := ts.winnerRank()
+	if ts.min {
+		winnerIndex = fps.getKminIndex(winnerRank)
+	} else {
+		winnerIndex = fps.getKmaxIndex(winnerRank)
+	}
+	return winnerIndex
+}
+
+func (fps fitnessPositions[F]) getKminIndex(k int) int {
+	//This would be faster by implementing quickSelect,
+	//and/or lower k specific implementations.
+	//another alternative is just to keep an inverted K sized heap
+	//replacing the root when the new entry is less than it
+	sort.Sort(fps)
+	return fps[k].i
+}
+func (fps fitnessPositions[F]) getKmaxIndex(k int) int {
+	sort.Sort(sort.Reverse(fps))
+	return fps[k].i
+}
+
+//Selection weights proportional to how much better than the worst
+//individual each one is. The worst keeps a small weight so it can
+//still be picked, and equal fitnesses make selection uniform.
+func proportionalWeights[F Number](fitnesses []F, min bool) []float64 {
+	worst, best := fitnesses[0], fitnesses[0]
+	for _, f := range fitnesses {
+		if (min && f > worst) || (!min && f < worst) {
+			worst = f
+		}
+		if (min && f < best) || (!min && f > best) {
+			best = f
+		}
+	}
+	spread := float64(best) - float64(worst)
+	if spread < 0 {
+		spread = -spread
+	}
+	floor := spread / float64(len(fitnesses))
+	if floor == 0 {
+		floor = 1
+	}
+	weights := make([]float64, len(fitnesses))
+	for i, f := range fitnesses {
+		d := float64(f) - float64(worst)
+		if d < 0 {
+			d = -d
+		}
+		weights[i] = d + floor
+	}
+	return weights
+}
+
+func cumulative(weights []float64) []float64 {
+	cum := make([]float64, len(weights))
+	var total float64
+	for i, w := range weights {
+		total += w
+		cum[i] = total
+	}
+	return cum
+}
+
+//Index of the first cumulative weight above x.
+func spin(cum []float64, x float64) int {
+	i := sort.SearchFloat64s(cum, x)
+	for i < len(cum)-1 && cum[i] <= x {
+		i++
+	}
+	if i == len(cum) {
+		i--
+	}
+	return i
+}
+
+//Picks individuals with probability given by a cumulative weights
+//slice, aligned with the population.
+type wheelSelector[G any, F Number] struct {
+	cum []float64
+	rp  *Ranked[G, F]
+	r   *rand.Rand
+}
+
+func (ws *wheelSelector[G, F]) Next() G {
+	return ws.rp.Pop[ws.NextIndex()]
+}
+
+func (ws *wheelSelector[G, F]) NextIndex() int {
+	x := ws.r.Float64() * ws.cum[len(ws.cum)-1]
+	return spin(ws.cum, x)
+}
+
+//Fitness proportional selection, see proportionalWeights.
+func NewRouletteSelectorBuilder[G any, F Number](r *rand.Rand, min bool) SelectorBuilder[G, F] {
+	return func(rp *Ranked[G, F]) Selector[G] {
+		return &wheelSelector[G, F]{cumulative(proportionalWeights(rp.Fitnesses, min)), rp, r}
+	}
+}
+
+//Linear ranking: the fittest individual is picked with probability
+//pressure/n and the least fit with (2-pressure)/n, pressure in [1, 2].
+func NewLinearRankSelectorBuilder[G any, F Number](pressure float64, r *rand.Rand, min bool) SelectorBuilder[G, F] {
+	return func(rp *Ranked[G, F]) Selector[G] {
+		n := len(rp.Pop)
+		weights := make([]float64, n)
+		for rank := 0; rank < n; rank++ {
+			w := pressure
+			if n > 1 {
+				w -= (2*pressure - 2) * float64(rank) / float64(n-1)
+			}
+			weights[rankIndex(rank, n, min)] = w
+		}
+		return &wheelSelector[G, F]{cumulative(weights), rp, r}
+	}
+}
+
+//Stochastic universal sampling: a single spin of a wheel with
+//len(rp.Pop) evenly spaced pointers picks a whole batch of parents,
+//with the same expectations as roulette selection but less variance.
+//Batches are handed out in random order, spinning again when exhausted.
+type SUSSelector[G any, F Number] struct {
+	cum   []float64
+	rp    *Ranked[G, F]
+	r     *rand.Rand
+	batch []int
+}
+
+func NewSUSSelectorBuilder[G any, F Number](r *rand.Rand, min bool) SelectorBuilder[G, F] {
+	return func(rp *Ranked[G, F]) Selector[G] {
+		return &SUSSelector[G, F]{cum: cumulative(proportionalWeights(rp.Fitnesses, min)), rp: rp, r: r}
+	}
+}
+
+func (ss *SUSSelector[G, F]) sample() {
+	n := len(ss.rp.Pop)
+	step := ss.cum[len(ss.cum)-1] / float64(n)
+	x := ss.r.Float64() * step
+	ss.batch = make([]int, n)
+	for i, j := range ss.r.Perm(n) {
+		ss.batch[j] = spin(ss.cum, x+float64(i)*step)
+	}
+}
+
+func (ss *SUSSelector[G, F]) Next() G {
+	return ss.rp.Pop[ss.NextIndex()]
+}
+
+func (ss *SUSSelector[G, F]) NextIndex() int {
+	if len(ss.batch) == 0 {
+		ss.sample()
+	}
+	i := ss.batch[0]
+	ss.batch = ss.batch[1:]
+	return i
+}
+
+//Picks uniformly among the fittest fraction of the population.
+type TruncationSelector[G any, F Number] struct {
+	k   int
+	rp  *Ranked[G, F]
+	r   *rand.Rand
+	min bool
+}
+
+func NewTruncationSelectorBuilder[G any, F Number](fraction float64, r *rand.Rand, min bool) SelectorBuilder[G, F] {
+	return func(rp *Ranked[G, F]) Selector[G] {
+		k := int(fraction * float64(len(rp.Pop)))
+		if k < 1 {
+			k = 1
+		} else if k > len(rp.Pop) {
+			k = len(rp.Pop)
+		}
+		return &TruncationSelector[G, F]{k, rp, r, min}
+	}
+}
+
+func (ts *TruncationSelector[G, F]) Next() G {
+	return ts.rp.Pop[ts.NextIndex()]
+}
+
+func (ts *TruncationSelector[G, F]) NextIndex() int {
+	return rankIndex(ts.r.Intn(ts.k), len(ts.rp.Pop), ts.min)
+}
+
+var _ Selector[int] = &TournamentSelector[int, uint]{}
+var _ Selector[int] = &wheelSelector[int, uint]{}
+var _ Selector[int] = &SUSSelector[int, uint]{}
+var _ Selector[int] = &TruncationSelector[int, uint]{}
@@ -0,0 +1,74 @@
+package evolve
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//Decides whether a run should end after the given progress, and
+//describes why.
+type StopCondition[G, P any, F Number] func(p *Progress[G, P, F]) (stop bool, reason string)
+
+func MaxGenerations[G, P any, F Number](n uint) StopCondition[G, P, F] {
+	return func(p *Progress[G, P, F]) (bool, string) {
+		return p.Generation >= n, fmt.Sprintf("reached %d generations", n)
+	}
+}
+
+//Stops when the best fitness didn't improve for n generations.
+func Stagnation[G, P any, F Number](n uint) StopCondition[G, P, F] {
+	return func(p *Progress[G, P, F]) (bool, string) {
+		return p.Generation-p.Improved >= n, fmt.Sprintf("no improvement for %d generations", n)
+	}
+}
+
+//Stops once a fitness at least as good as target is found.
+func TargetFitness[G, P any, F Number](target F) StopCondition[G, P, F] {
+	return func(p *Progress[G, P, F]) (bool, string) {
+		return p.BestSoFar <= target, fmt.Sprintf("reached target fitness %v", target)
+	}
+}
+
+func EvaluationBudget[G, P any, F Number](n uint) StopCondition[G, P, F] {
+	return func(p *Progress[G, P, F]) (bool, string) {
+		return p.Evaluations >= n, fmt.Sprintf("reached %d evaluations", n)
+	}
+}
+
+//Stops when the next generation is expected to end past the limit,
+//extrapolating from the average generation time.
+func WallClock[G, P any, F Number](limit time.Duration) StopCondition[G, P, F] {
+	return func(p *Progress[G, P, F]) (bool, string) {
+		ng := int64(p.Generation)
+		return (p.Elapsed.Nanoseconds()*(ng+1))/ng > limit.Nanoseconds(),
+			fmt.Sprintf("reached time limit %v", limit)
+	}
+}
+
+//Stops as soon as any of the conditions does, with its reason.
+func AnyOf[G, P any, F Number](conditions ...StopCondition[G, P, F]) StopCondition[G, P, F] {
+	return func(p *Progress[G, P, F]) (bool, string) {
+		for _, condition := range conditions {
+			if stop, reason := condition(p); stop {
+				return true, reason
+			}
+		}
+		return false, ""
+	}
+}
+
+//Stops when all the conditions do, with all their reasons.
+func AllOf[G, P any, F Number](conditions ...StopCondition[G, P, F]) StopCondition[G, P, F] {
+	return func(p *Progress[G, P, F]) (bool, string) {
+		reasons := make([]string, len(conditions))
+		for i, condition := range conditions {
+			stop, reason := condition(p)
+			if !stop {
+				return false, ""
+			}
+			reasons[i] = reason
+		}
+		return true, strings.Join(reasons, " and ")
+	}
+}
@@ -1,10 +1,13 @@
 package guillotine
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"sort"
 	"time"
+
+	"github.com/rdarder/guillotine/evolve"
 )
 
 var _ = fmt.Println
@@ -46,178 +49,283 @@ func (pop Population) evaluate(spec *CutSpec) {
 	}
 }
 */
-type RankedPopulation struct {
-	Pop       Population
-	Fitnesses []uint
-}
+//Population sorted by ascending fitness, see evolve.Ranked
+type RankedPopulation = evolve.Ranked[Genotype, uint]
 
-func (rp *RankedPopulation) Less(i, j int) bool {
-	return rp.Fitnesses[i] < rp.Fitnesses[j]
-}
-func (rp *RankedPopulation) Swap(i, j int) {
-	rp.Pop[i], rp.Pop[j] = rp.Pop[j], rp.Pop[i]
-	rp.Fitnesses[i], rp.Fitnesses[j] = rp.Fitnesses[j], rp.Fitnesses[i]
+//Picks parents out of a ranked population, one at a time. Builders get
+//the population as left by Evaluate, sorted by ascending fitness.
+type Selector = evolve.Selector[Genotype]
+type SelectorBuilder = evolve.SelectorBuilder[Genotype, uint]
+
+type TournamentSelector = evolve.TournamentSelector[Genotype, uint]
+
+func NewTournamentSelectorBuilder(size int, p float32, r *rand.Rand, min bool) SelectorBuilder {
+	return evolve.NewTournamentSelectorBuilder[Genotype, uint](size, p, r, min)
 }
-func (rp *RankedPopulation) Len() int { return len(rp.Pop) }
 
-type Selector interface {
-	next() Genotype
+func (pop Population) checkEvenSize() {
+	if len(pop)%2 != 0 {
+		panic("Population size must be even")
+	}
 }
-type SelectorBuilder func(rp *RankedPopulation) Selector
 
-type TournamentSelector struct {
-	size int
-	buf  FitnessPositions
-	p    float32
-	rp   *RankedPopulation
-	r    *rand.Rand
-	min  bool
+type GeneticAlgorithm struct {
+	Spec            *CutSpec
+	Evaluator       Fitness
+	Mutator         Mutator
+	Breeder         Crossover
+	SelectorBuilder SelectorBuilder
+	//Every random choice of a run is drawn from R, selectors and
+	//mutators included, so runs with equally seeded R are identical
+	//(unless they stop on wall clock time).
+	R               *rand.Rand
+	EliteSize       uint
+	PopulationSize  uint
+	Generations     uint
+	//Optional, records the best layout of every generation
+	Recorder *GifRecorder
+	//Amount of goroutines evaluating the population, 0 or 1 evaluates
+	//sequentially. Evaluator must be safe for concurrent use.
+	Workers int
+	//Optional, notified of the progress of every generation
+	Observer Observer
+	//When to end a run, defaults to MaxGenerations(Generations)
+	Stop StopCondition
+	//Optional, the Source behind R, reseeded on every generation.
+	//Required for checkpoints
+	Source *Source
+	//Optional, genotypes included in the initial population, see EncodeLayout
+	Seeds []Genotype
+	//Optional, tunes mutation on every generation, see AdaptiveMutator
+	Adapter MutationAdapter
+	//Optional, selection by shared fitness
+	Sharing *FitnessSharing
+	//Replace breeding and elitism by deterministic crowding
+	Crowding bool
+	//Optional, random genotypes injected when diversity drops
+	Immigrants *RandomImmigrants
+	//Optional, replaces individuals one child at a time instead of
+	//breeding whole generations. Ignored on Crowding, and Immigrants
+	//aren't injected.
+	SteadyState *SteadyState
+	//Optional, breeds generations as a biased random key GA. Ignored
+	//on Crowding and SteadyState.
+	BRKGA *BRKGA
+	//Optional, skips evaluating genotypes seen before. Evaluations
+	//in Progress still count every individual.
+	Cache *FitnessCache
+	//Best parent fitness of each child of the last generation, keyed by
+	//the child's index in it, only kept when there's an Adapter.
+	lineage map[int]uint
 }
 
-func NewTournamentSelectorBuilder(size int, p float32, r *rand.Rand, min bool) SelectorBuilder {
-	return func(rp *RankedPopulation) Selector {
-		return &TournamentSelector{
-			size: size,
-			buf:  make([]FitnessPosition, size),
-			p:    p,
-			r:    r,
-			rp:   rp,
-			min:  min,
-		}
+//Fitness of a single genotype, looked up on the Cache when there's one.
+func (ga *GeneticAlgorithm) fitness(g Genotype) uint {
+	if ga.Cache != nil {
+		return ga.Cache.fitness(ga.Spec, ga.Evaluator, g)
 	}
+	return ga.Evaluator(GetPhenotype(ga.Spec, g))
 }
 
-type FitnessPosition struct {
-	i       int
-	fitness uint
-}
+type engine = evolve.Engine[Genotype, *LayoutTree, uint]
 
-type FitnessPositions []FitnessPosition
+//The run loop of the GeneticAlgorithm, breeding with nextGeneration.
+func (ga *GeneticAlgorithm) engine() *engine {
+	nboards := uint16(len(ga.Spec.Boards))
+	e := &engine{
+		Random: func(r *rand.Rand) Genotype {
+			return NewRandomGenotype(nboards, r)
+		},
+		Decode: func(g Genotype) *LayoutTree {
+			return GetPhenotype(ga.Spec, g)
+		},
+		Fitness:         ga.fitness,
+		Mutator:         ga.Mutator,
+		Breeder:         ga.Breeder,
+		SelectorBuilder: ga.SelectorBuilder,
+		R:               ga.R,
+		EliteSize:       ga.EliteSize,
+		PopulationSize:  ga.PopulationSize,
+		Generations:     ga.Generations,
+		Workers:         ga.Workers,
+		OnChildren:      ga.children,
+		NextGeneration:  ga.nextGeneration,
+		Observer:        ga.observe,
+		Stop:            ga.Stop,
+	}
+	if ga.Sharing != nil {
+		e.SelectionView = ga.Sharing.share
+	}
+	return e
+}
 
-func (fps FitnessPositions) Len() int           { return len(fps) }
-func (fps FitnessPositions) Less(i, j int) bool { return fps[i].fitness < fps[j].fitness }
-func (fps FitnessPositions) Swap(i, j int)      { fps[i], fps[j] = fps[j], fps[i] }
+func (ga *GeneticAlgorithm) Evaluate(pop Population) *RankedPopulation {
+	return ga.engine().Evaluate(pop)
+}
 
-func (ts *TournamentSelector) winnerRank() int {
-	//This could be faster if modelled with a
-	//negative binomial distribution generator
-	for {
-		for i := 0; i < ts.size; i++ {
-			if ts.r.Float32() > ts.p {
-				return i
+//Records the best parent fitness of each child bred by the engine,
+//when there's an Adapter, then lets Immigrants replace some of them.
+func (ga *GeneticAlgorithm) children(rp *RankedPopulation, next []Genotype, parents [][2]int) {
+	if ga.Adapter != nil {
+		ga.lineage = make(map[int]uint, len(next))
+		for i := int(ga.EliteSize); i < len(next); i++ {
+			parent := rp.Fitnesses[parents[i][0]]
+			if f := rp.Fitnesses[parents[i][1]]; f < parent {
+				parent = f
 			}
+			ga.lineage[i] = parent
+		}
+	}
+	if ga.Immigrants != nil {
+		n := ga.Immigrants.immigrate(rp, next, ga.EliteSize, uint16(len(ga.Spec.Boards)), ga.R)
+		for i := len(next) - n; i < len(next); i++ {
+			delete(ga.lineage, i)
 		}
 	}
 }
 
-func (ts *TournamentSelector) next() Genotype {
-	fps := ts.buf
-	for i := 0; i < ts.size; i++ {
-		ri := ts.r.Intn(len(ts.rp.Fitnesses))
-		fps[i].i = ri
-		fps[i].fitness = ts.rp.Fitnesses[ri]
+//Fraction of the children bred by the last generation that are fitter
+//than both their parents, given the fitness of each individual in the
+//order they were bred.
+func (ga *GeneticAlgorithm) successRate(fitness []uint) float64 {
+	var children, successes int
+	for i, f := range fitness {
+		if parent, ok := ga.lineage[i]; ok {
+			children++
+			if f < parent {
+				successes++
+			}
+		}
 	}
-	var winnerIndex int
-	winnerRank := ts.winnerRank()
-	if ts.min {
-		winnerIndex = fps.getKminIndex(winnerRank)
-	} else {
-		winnerIndex = fps.getKmaxIndex(winnerRank)
+	if children == 0 {
+		return 0
 	}
-	//	fmt.Printf("candidates: %v. winnerRank: %v, winnerIndex: %v\n", fps, winnerRank, winnerIndex)
-	return ts.rp.Pop[winnerIndex]
+	return float64(successes) / float64(children)
 }
 
-func (fps FitnessPositions) getKminIndex(k int) int {
-	//This would be faster by implementing quickSelect,
-	//and/or lower k specific implementations.
-	//another alternative is just to keep an inverted K sized heap
-	//replacing the root when the new entry is less than it
-	sort.Sort(fps)
-	return fps[k].i
-}
-func (fps FitnessPositions) getKmaxIndex(k int) int {
-	sort.Reverse(fps)
-	return fps[k].i
+//Snapshot of a run, taken after evaluating each generation. Phenotype
+//is the best layout of the generation, BestPhenotype the best one
+//across all generations.
+type Progress = evolve.Progress[Genotype, *LayoutTree, uint]
+
+//Called by the GeneticAlgorithm on each generation, must not modify
+//the population.
+type Observer = evolve.Observer[Genotype, *LayoutTree, uint]
+
+type RunResult struct {
+	Generations uint
+	Evaluations uint
+	Layout      *LayoutTree
+	Fitness     uint
+	Population  *RankedPopulation
+	//Why the run ended, see StopCondition
+	Reason string
+	//Seed of the GeneticAlgorithm Source, when there's one
+	Seed int64
 }
 
-func (pop Population) checkEvenSize() {
-	if len(pop)%2 != 0 {
-		panic("Population size must be even")
+func (ga *GeneticAlgorithm) observe(p *Progress) {
+	if ga.Recorder != nil {
+		ga.Recorder.Record(p.Phenotype)
+	}
+	if ga.Observer != nil {
+		ga.Observer(p)
 	}
 }
 
-type GeneticAlgorithm struct {
-	Spec            *CutSpec
-	Evaluator       Fitness
-	Mutator         Mutator
-	Breeder         Crossover
-	SelectorBuilder SelectorBuilder
-	R               *rand.Rand
-	EliteSize       uint
-	PopulationSize  uint
-	Generations     uint
+//Evaluates a random population, along with the Seeds, the first
+//generation of a run.
+func (ga *GeneticAlgorithm) first(start time.Time) *Progress {
+	e := ga.engine()
+	for _, seed := range ga.Seeds {
+		e.Seeds = append(e.Seeds, seed.copy())
+	}
+	return e.First(start)
 }
 
-func (ga GeneticAlgorithm) breed(p1, p2 Genotype) (c1, c2 Genotype) {
-	c1, c2 = ga.Breeder(p1, p2, ga.R)
-	ga.Mutator(c1, ga.R)
-	ga.Mutator(c2, ga.R)
-	return c1, c2
+func (ga *GeneticAlgorithm) step(p *Progress, start time.Time) *Progress {
+	return ga.engine().Step(p, start)
 }
 
-func (ga *GeneticAlgorithm) Evaluate(pop Population) (rp *RankedPopulation) {
-	fitness := make([]uint, len(pop))
-	for i, genotype := range pop {
-		phenotype := GetPhenotype(ga.Spec, genotype)
-		fitness[i] = ga.Evaluator(phenotype)
+//The generation after p, evaluated and sorted.
+func (ga *GeneticAlgorithm) nextGeneration(p *Progress) *RankedPopulation {
+	var rp *RankedPopulation
+	var success float64
+	//every generation draws from its own seed, so checkpoints don't
+	//need the draws that came before them
+	if ga.Source != nil {
+		ga.Source.Reseed(p.Generation)
+	}
+	if ga.Crowding {
+		rp, success = ga.crowd(p.Population)
+	} else if ga.SteadyState != nil {
+		rp, success = ga.steady(p.Population)
+	} else if ga.BRKGA != nil {
+		rp = ga.Evaluate(ga.nextBRKGA(p.Population))
+	} else {
+		e := ga.engine()
+		pop := e.Next(p.Population)
+		rp = &RankedPopulation{Pop: pop, Fitnesses: e.Fitnesses(pop)}
+		if ga.Adapter != nil {
+			success = ga.successRate(rp.Fitnesses)
+		}
+		sort.Sort(rp)
+	}
+	if ga.Adapter != nil {
+		ga.Adapter.Adapt(p.Generation+1, success)
 	}
-	rp = &RankedPopulation{pop, fitness}
-	//	fmt.Println(rp.Fitnesses)
-	sort.Sort(rp)
-	//	fmt.Println(rp.Fitnesses)
 	return rp
 }
 
-func (ga *GeneticAlgorithm) Next(rp *RankedPopulation) Population {
-	selector := ga.SelectorBuilder(rp)
-	psize := uint(len(rp.Pop))
-	pepsi := make([]Genotype, psize)
-	copy(pepsi[:ga.EliteSize], rp.Pop[:ga.EliteSize])
-	for i := ga.EliteSize; i < psize; i++ {
-		p1, p2 := selector.next(), selector.next()
-		c1, c2 := ga.breed(p1, p2)
-		pepsi[i] = c1
-		if i < psize-1 {
-			i++
-			pepsi[i] = c2
-		}
+func (ga *GeneticAlgorithm) stopCondition() StopCondition {
+	if ga.Stop != nil {
+		return ga.Stop
 	}
-	return pepsi
+	return MaxGenerations(ga.Generations)
 }
 
-func (ga *GeneticAlgorithm) Run() *LayoutTree {
-	pop := NewRandomPopulation(uint16(len(ga.Spec.Boards)), ga.PopulationSize, ga.R)
-	rankedPop := ga.Evaluate(pop)
-	for i := uint(1); i < ga.Generations; i++ {
-		pop = ga.Next(rankedPop)
-		rankedPop = ga.Evaluate(pop)
+//Evolves a random population until stop says so, or until ctx is done.
+//On cancellation, the result holds the best layout found so far along
+//with the context's error.
+func (ga *GeneticAlgorithm) run(ctx context.Context, stop StopCondition) (*RunResult, error) {
+	start := time.Now()
+	return ga.evolve(ctx, ga.first(start), start, stop)
+}
+
+//Evolves from the generation in p until stop says so.
+func (ga *GeneticAlgorithm) evolve(ctx context.Context, p *Progress, start time.Time, stop StopCondition) (*RunResult, error) {
+	er, err := ga.engine().Evolve(ctx, p, start, stop)
+	result := &RunResult{
+		Generations: er.Generations,
+		Evaluations: er.Evaluations,
+		Layout:      er.Phenotype,
+		Fitness:     er.Fitness,
+		Population:  er.Population,
+		Reason:      er.Reason,
+	}
+	if ga.Source != nil {
+		result.Seed, _ = ga.Source.State()
 	}
-	return GetPhenotype(ga.Spec, rankedPop.Pop[0])
+	return result, err
+}
+
+//Runs until ga.Stop, or ga.Generations if there's no Stop condition.
+func (ga *GeneticAlgorithm) RunContext(ctx context.Context) (*RunResult, error) {
+	return ga.run(ctx, ga.stopCondition())
+}
+
+//Like RunContext, but also stops when the next generation is expected
+//to end past the time limit.
+func (ga *GeneticAlgorithm) TimeBoundedRunContext(ctx context.Context, limit time.Duration) (*RunResult, error) {
+	return ga.run(ctx, AnyOf(ga.stopCondition(), WallClock(limit)))
+}
+
+func (ga *GeneticAlgorithm) Run() *LayoutTree {
+	result, _ := ga.RunContext(context.Background())
+	return result.Layout
 }
 
 func (ga *GeneticAlgorithm) TimeBoundedRun(limit time.Duration) (gn uint, lt *LayoutTree) {
-	start := time.Now()
-	pop := NewRandomPopulation(uint16(len(ga.Spec.Boards)), ga.PopulationSize, ga.R)
-	rankedPop := ga.Evaluate(pop)
-	for i := uint(1); i < ga.Generations; i++ {
-		ng := int64(i)
-		if (time.Since(start).Nanoseconds()*(ng+1))/ng > limit.Nanoseconds() {
-			return i, GetPhenotype(ga.Spec, rankedPop.Pop[0])
-		} else {
-			pop = ga.Next(rankedPop)
-			rankedPop = ga.Evaluate(pop)
-		}
-	}
-	return ga.Generations, GetPhenotype(ga.Spec, rankedPop.Pop[0])
+	result, _ := ga.TimeBoundedRunContext(context.Background(), limit)
+	return result.Generations, result.Layout
 }
@@ -0,0 +1,129 @@
+package guillotine
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestParallelEvaluate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	spec := NewRandomSpec(12, 40, 50, r, false)
+	pop := NewRandomPopulation(12, 101, r)
+	sequential := &GeneticAlgorithm{Spec: spec, Evaluator: (*LayoutTree).Area}
+	parallel := &GeneticAlgorithm{Spec: spec, Evaluator: (*LayoutTree).Area, Workers: 8}
+	rp1 := sequential.Evaluate(append(Population{}, pop...))
+	rp2 := parallel.Evaluate(append(Population{}, pop...))
+	for i := range rp1.Pop {
+		if rp1.Fitnesses[i] != rp2.Fitnesses[i] || &rp1.Pop[i][0] != &rp2.Pop[i][0] {
+			t.Fatalf("Expected same ranking, differs at %v: %v vs %v", i, rp1.Fitnesses, rp2.Fitnesses)
+		}
+	}
+}
+
+func testGA(r *rand.Rand, nboards int) *GeneticAlgorithm {
+	return &GeneticAlgorithm{
+		Spec:      NewRandomSpec(nboards, 40, 50, r, false),
+		Evaluator: (*LayoutTree).Area,
+		Mutator: CompoundWeightConfigMutator{
+			Weight: NormalWeightMutator{Mean: 3, StdDev: 1},
+			Config: NormalConfigMutator{Mean: 3, StdDev: 1},
+		}.Mutate,
+		Breeder:         UniformCrossover,
+		SelectorBuilder: NewTournamentSelectorBuilder(4, 0.7, r, true),
+		R:               r,
+		EliteSize:       2,
+		PopulationSize:  20,
+		Generations:     100,
+	}
+}
+
+func TestRunContextCancel(t *testing.T) {
+	ga := testGA(rand.New(rand.NewSource(1)), 8)
+	ctx, cancel := context.WithCancel(context.Background())
+	ga.Observer = func(p *Progress) {
+		if p.Generation == 3 {
+			cancel()
+		}
+	}
+	result, err := ga.RunContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("Expected run to be canceled, got %v", err)
+	}
+	if result.Generations != 3 || result.Layout == nil {
+		t.Errorf("Expected a layout after 3 generations, got %+v", result)
+	}
+}
+
+func TestIslandModel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	model := &IslandModel{
+		Topology:          RingTopology,
+		Selection:         BestMigrants,
+		MigrationInterval: 3,
+		Migrants:          2,
+		R:                 r,
+		Stop:              MaxGenerations(10),
+	}
+	for i := 0; i < 3; i++ {
+		island := testGA(rand.New(rand.NewSource(r.Int63())), 8)
+		if i > 0 {
+			island.Spec = model.Islands[0].Spec
+		}
+		model.Islands = append(model.Islands, island)
+	}
+	result, err := model.RunContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Generations != 10 || len(result.Population.Pop) != 60 {
+		t.Errorf("Expected 60 individuals after 10 generations, got %v after %v",
+			len(result.Population.Pop), result.Generations)
+	}
+	if result.Fitness != result.Population.Fitnesses[0] {
+		t.Errorf("Expected best fitness %v to lead the merged population, got %v",
+			result.Fitness, result.Population.Fitnesses[0])
+	}
+}
+
+func TestSingleIsland(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	if dests := RandomTopology(0, 1, r); len(dests) != 0 {
+		t.Errorf("Expected no destinations for a single island, got %v", dests)
+	}
+	if _, err := (&IslandModel{}).RunContext(context.Background()); err == nil {
+		t.Error("Expected an error for a model without islands")
+	}
+	model := &IslandModel{
+		Islands:           []*GeneticAlgorithm{testGA(r, 8)},
+		Topology:          RandomTopology,
+		Selection:         BestMigrants,
+		MigrationInterval: 2,
+		Migrants:          2,
+		R:                 r,
+		Stop:              MaxGenerations(5),
+	}
+	if result, err := model.RunContext(context.Background()); err != nil || result.Generations != 5 {
+		t.Errorf("Expected a single island to run 5 generations, got %+v (%v)", result, err)
+	}
+}
+
+func TestAdaptiveMutator(t *testing.T) {
+	ga := testGA(rand.New(rand.NewSource(1)), 10)
+	mutator := NewAdaptiveMutator(4, 4)
+	ga.Mutator, ga.Adapter = mutator.Mutate, mutator
+	ga.Stop = MaxGenerations(6)
+	ga.RunContext(context.Background())
+	if len(mutator.Trajectory) != 5 {
+		t.Fatalf("Expected 5 adaptations, got %v", len(mutator.Trajectory))
+	}
+	for _, step := range mutator.Trajectory {
+		if step.SuccessRate < 0 || step.SuccessRate > 1 || step.WeightMean < mutator.MinMean {
+			t.Errorf("Unexpected adaptation step %+v", step)
+		}
+	}
+	mutator.Adapt(7, 0.5)
+	if last := mutator.Trajectory[5]; last.WeightMean <= mutator.Trajectory[4].WeightMean {
+		t.Errorf("Expected mutation to grow on success, got %+v", last)
+	}
+}
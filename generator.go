@@ -55,7 +55,7 @@ func NewRandomSpec(nboards int, width, height uint, r *rand.Rand, limitWidth boo
 			mb.Boards = append(mb.Boards, b2)
 		}
 	}
-	spec = &CutSpec{Boards: mb.Boards}
+	spec = &CutSpec{Boards: mb.Boards, TotalArea: width * height}
 	if limitWidth{
 		spec.MaxWidth = width
 	}
@@ -1,6 +1,11 @@
 package guillotine
 
-import "math/rand"
+import (
+	"encoding/json"
+	"math/rand"
+
+	"github.com/rdarder/guillotine/evolve"
+)
 
 type WeightedJoin struct {
 	weight float32
@@ -8,6 +13,27 @@ type WeightedJoin struct {
 	config Join
 }
 
+//Serialized form of a WeightedJoin
+type weightedJoinJSON struct {
+	W float32 `json:"w"`
+	I uint16  `json:"i"`
+	J uint16  `json:"j"`
+	C Join    `json:"c"`
+}
+
+func (wj WeightedJoin) MarshalJSON() ([]byte, error) {
+	return json.Marshal(weightedJoinJSON{wj.weight, wj.i, wj.j, wj.config})
+}
+
+func (wj *WeightedJoin) UnmarshalJSON(b []byte) error {
+	var v weightedJoinJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	wj.weight, wj.i, wj.j, wj.config = v.W, v.I, v.J, v.C
+	return nil
+}
+
 type Genotype []WeightedJoin
 
 func (g Genotype) copy() Genotype {
@@ -51,7 +77,7 @@ func freshPair(p1, p2 Genotype) (n int, c1, c2 Genotype) {
 	}
 }
 
-type Crossover func(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype)
+type Crossover = evolve.Crossover[Genotype]
 
 func UniformCrossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
 	n, c1, c2 := freshPair(p1, p2)
@@ -75,7 +101,7 @@ var _ Crossover = UniformCrossover
 
 func OnePointCrossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
 	n, c1, c2 := freshPair(p1, p2)
-	cpoint := rand.Intn(n)
+	cpoint := r.Intn(n)
 	copy(c1[:cpoint], p1[:cpoint])
 	copy(c1[cpoint:], p2[cpoint:])
 
@@ -88,8 +114,8 @@ var _ Crossover = OnePointCrossover
 
 func TwoPointCrossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
 	n, c1, c2 := freshPair(p1, p2)
-	point1 := rand.Intn(n)
-	point2 := rand.Intn(n)
+	point1 := r.Intn(n)
+	point2 := r.Intn(n)
 	if point1 > point2 {
 		point1, point2 = point2, point1
 	}
@@ -106,7 +132,17 @@ func TwoPointCrossover(p1, p2 Genotype, r *rand.Rand) (c1, c2 Genotype) {
 
 var _ Crossover = TwoPointCrossover
 
-type Mutator func(Genotype, *rand.Rand)
+type Mutator = evolve.Mutator[Genotype]
+
+//Normally distributed amount of mutations, negative samples meaning
+//none: converting them to unsigned integers is platform dependent.
+func normalCount(mean, stddev float64, r *rand.Rand) int {
+	x := r.NormFloat64()*stddev + mean
+	if x < 0 {
+		return 0
+	}
+	return int(x)
+}
 
 //In-place chromosome mutation, by replacing some of the
 //gene weights by a new random weight.
@@ -122,10 +158,9 @@ type NormalWeightMutator struct {
 }
 
 func (p NormalWeightMutator) Mutate(c Genotype, r *rand.Rand) {
-	take := uint16(r.NormFloat64()*p.StdDev + p.Mean)
-	for ; take > 0; take-- {
-		i := rand.Intn(len(c))
-		c[i].weight = rand.Float32()
+	for take := normalCount(p.Mean, p.StdDev, r); take > 0; take-- {
+		i := r.Intn(len(c))
+		c[i].weight = r.Float32()
 	}
 }
 
@@ -134,10 +169,9 @@ type NormalConfigMutator struct {
 }
 
 func (p NormalConfigMutator) Mutate(c Genotype, r *rand.Rand) {
-	take := uint16(r.NormFloat64()*p.StdDev + p.Mean)
-	for ; take > 0; take-- {
-		i := rand.Intn(len(c))
-		c[i].config = Join(rand.Intn(8))
+	for take := normalCount(p.Mean, p.StdDev, r); take > 0; take-- {
+		i := r.Intn(len(c))
+		c[i].config = Join(r.Intn(8))
 	}
 }
 
@@ -0,0 +1,219 @@
+package guillotine
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+//Destination islands for the migrants of island i, out of n islands.
+type Topology func(i, n int, r *rand.Rand) []int
+
+//Each island sends migrants to the next one.
+func RingTopology(i, n int, r *rand.Rand) []int {
+	return []int{(i + 1) % n}
+}
+
+//Each island sends migrants to every other island.
+func FullTopology(i, n int, r *rand.Rand) []int {
+	dests := make([]int, 0, n-1)
+	for j := 0; j < n; j++ {
+		if j != i {
+			dests = append(dests, j)
+		}
+	}
+	return dests
+}
+
+//Each island sends migrants to another island chosen at random on
+//every migration. A single island has none to send them to.
+func RandomTopology(i, n int, r *rand.Rand) []int {
+	if n < 2 {
+		return nil
+	}
+	j := r.Intn(n - 1)
+	if j >= i {
+		j++
+	}
+	return []int{j}
+}
+
+var _ Topology = RingTopology
+var _ Topology = FullTopology
+var _ Topology = RandomTopology
+
+//Chooses k migrants out of a ranked population, returning their
+//positions in it.
+type MigrantSelection func(rp *RankedPopulation, k int, r *rand.Rand) []int
+
+func BestMigrants(rp *RankedPopulation, k int, r *rand.Rand) []int {
+	migrants := make([]int, k)
+	for i := range migrants {
+		migrants[i] = i
+	}
+	return migrants
+}
+
+func RandomMigrants(rp *RankedPopulation, k int, r *rand.Rand) []int {
+	return r.Perm(len(rp.Pop))[:k]
+}
+
+var _ MigrantSelection = BestMigrants
+var _ MigrantSelection = RandomMigrants
+
+//Several GeneticAlgorithms evolving their own populations of the same
+//spec concurrently, exchanging individuals every MigrationInterval
+//generations. Migrants replace the worst individuals of the
+//destination islands.
+//Islands can use different operators and parameters, but each one
+//needs its own R, as they run in parallel.
+type IslandModel struct {
+	Islands           []*GeneticAlgorithm
+	Topology          Topology
+	Selection         MigrantSelection
+	MigrationInterval uint
+	Migrants          uint
+	//Drives topology and migrant selection
+	R *rand.Rand
+	//Evaluated on the merged progress of all islands, defaults to
+	//MaxGenerations of the first island.
+	Stop StopCondition
+	//Optional, notified of the merged progress of every generation
+	Observer Observer
+}
+
+//Runs all the islands in lockstep until Stop says so. The Population
+//of the result merges the final populations of all islands.
+func (im *IslandModel) RunContext(ctx context.Context) (*RunResult, error) {
+	if len(im.Islands) == 0 {
+		return nil, fmt.Errorf("island models need at least one island")
+	}
+	start := time.Now()
+	stop := im.Stop
+	if stop == nil {
+		stop = im.Islands[0].stopCondition()
+	}
+	islands := make([]*Progress, len(im.Islands))
+	im.parallel(func(i int, ga *GeneticAlgorithm) {
+		islands[i] = ga.first(start)
+	})
+	p := im.merge(nil, islands, start)
+	var err error
+	var reason string
+	for {
+		var done bool
+		if done, reason = stop(p); done {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			reason = err.Error()
+			break
+		}
+		im.parallel(func(i int, ga *GeneticAlgorithm) {
+			islands[i] = ga.step(islands[i], start)
+		})
+		if im.MigrationInterval > 0 && islands[0].Generation%im.MigrationInterval == 0 {
+			im.migrate(islands)
+		}
+		p = im.merge(p, islands, start)
+	}
+	return &RunResult{
+		Generations: p.Generation,
+		Evaluations: p.Evaluations,
+		Layout:      p.BestPhenotype,
+		Fitness:     p.BestSoFar,
+		Population:  p.Population,
+		Reason:      reason,
+	}, err
+}
+
+func (im *IslandModel) parallel(f func(i int, ga *GeneticAlgorithm)) {
+	var wg sync.WaitGroup
+	for i, ga := range im.Islands {
+		wg.Add(1)
+		go func(i int, ga *GeneticAlgorithm) {
+			defer wg.Done()
+			f(i, ga)
+		}(i, ga)
+	}
+	wg.Wait()
+}
+
+//Every island picks its migrants before any of them arrives, so the
+//outcome doesn't depend on the islands order.
+func (im *IslandModel) migrate(islands []*Progress) {
+	n := len(islands)
+	incoming := make([]RankedPopulation, n)
+	for i, island := range islands {
+		rp := island.Population
+		k := int(im.Migrants)
+		if k > len(rp.Pop) {
+			k = len(rp.Pop)
+		}
+		migrants := im.Selection(rp, k, im.R)
+		for _, dest := range im.Topology(i, n, im.R) {
+			for _, m := range migrants {
+				incoming[dest].Pop = append(incoming[dest].Pop, rp.Pop[m].copy())
+				incoming[dest].Fitnesses = append(incoming[dest].Fitnesses, rp.Fitnesses[m])
+			}
+		}
+	}
+	for i, island := range islands {
+		rp := island.Population
+		arrivals := &incoming[i]
+		if len(arrivals.Pop) == 0 {
+			continue
+		}
+		if len(arrivals.Pop) > len(rp.Pop) {
+			arrivals.Pop, arrivals.Fitnesses = arrivals.Pop[:len(rp.Pop)], arrivals.Fitnesses[:len(rp.Pop)]
+		}
+		worst := len(rp.Pop) - len(arrivals.Pop)
+		copy(rp.Pop[worst:], arrivals.Pop)
+		copy(rp.Fitnesses[worst:], arrivals.Fitnesses)
+		sort.Sort(rp)
+		im.Islands[i].engine().Rank(island, rp)
+	}
+}
+
+//The populations of all islands, ranked together.
+func mergePopulations(islands []*Progress) *RankedPopulation {
+	merged := &RankedPopulation{}
+	for _, island := range islands {
+		merged.Pop = append(merged.Pop, island.Population.Pop...)
+		merged.Fitnesses = append(merged.Fitnesses, island.Population.Fitnesses...)
+	}
+	sort.Sort(merged)
+	return merged
+}
+
+//Progress of the whole model, the best island's layout, the average of
+//the islands means and their merged populations.
+func (im *IslandModel) merge(prev *Progress, islands []*Progress, start time.Time) *Progress {
+	p := &Progress{
+		Generation: islands[0].Generation,
+		Population: mergePopulations(islands),
+		Elapsed:    time.Since(start),
+	}
+	if prev != nil {
+		p.BestSoFar, p.BestPhenotype, p.Improved = prev.BestSoFar, prev.BestPhenotype, prev.Improved
+	}
+	best := islands[0]
+	for _, island := range islands {
+		p.Evaluations += island.Evaluations
+		p.Mean += island.Mean / float64(len(islands))
+		if island.Best < best.Best {
+			best = island
+		}
+	}
+	p.Best, p.Phenotype = best.Best, best.Phenotype
+	if p.BestPhenotype == nil || p.Best < p.BestSoFar {
+		p.BestSoFar, p.BestPhenotype, p.Improved = p.Best, p.Phenotype, p.Generation
+	}
+	if im.Observer != nil {
+		im.Observer(p)
+	}
+	return p
+}
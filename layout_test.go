@@ -71,3 +71,27 @@ func TestBiggerTree(t *testing.T) {
 		wrongArea(t, lt, 225, area)
 	}
 }
+
+func TestCuts(t *testing.T) {
+	spec := newCutSpec(0, 0).Add(1, 6).Add(4, 5).Add(5, 2)
+	lt := NewLayoutTree(spec)
+	lt.take(0, 1, JOIN.direct(HORIZONTAL))
+	lt.take(0, 2, JOIN.direct(VERTICAL))
+	expected := []Cut{
+		{0, 6, 5, 6, 1},
+		{1, 0, 1, 6, 2},
+		{1, 5, 5, 5, 3},
+	}
+	cuts := lt.Cuts(Board{})
+	if len(cuts) != len(expected) {
+		t.Fatalf("Expected cuts %v, got %v", expected, cuts)
+	}
+	for i := range cuts {
+		if cuts[i] != expected[i] {
+			t.Errorf("Expected cut %v to be %v, got %v", i, expected[i], cuts[i])
+		}
+	}
+	if cuts := lt.Cuts(Board{7, 8}); len(cuts) != 4 || cuts[0] != (Cut{5, 0, 5, 8, 1}) {
+		t.Errorf("Expected a first trim cut on a wider sheet, got %v", cuts)
+	}
+}
@@ -0,0 +1,112 @@
+package guillotine
+
+import (
+	"math"
+	"math/rand"
+)
+
+//Mutators that rearrange the weight ordering, which is what decides
+//which joins are tried first. As with NormalWeightMutator, the amount of
+//mutations on each individual is normally distributed by Mean and
+//StdDev, and mutations are done with replacement.
+
+//Moves weights by a normally distributed amount with standard
+//deviation Sigma, so joins shift a few places in the ordering instead of
+//landing anywhere.
+type GaussianWeightMutator struct {
+	Mean, StdDev float64
+	Sigma        float64
+}
+
+func (p GaussianWeightMutator) Mutate(c Genotype, r *rand.Rand) {
+	for take := normalCount(p.Mean, p.StdDev, r); take > 0; take-- {
+		i := r.Intn(len(c))
+		c[i].weight = bounded(float64(c[i].weight) + r.NormFloat64()*p.Sigma)
+	}
+}
+
+//Exchanges the weights of two genes, so the joins trade priorities.
+type SwapMutator struct {
+	Mean, StdDev float64
+}
+
+func (p SwapMutator) Mutate(c Genotype, r *rand.Rand) {
+	for take := normalCount(p.Mean, p.StdDev, r); take > 0; take-- {
+		i, j := r.Intn(len(c)), r.Intn(len(c))
+		c[i].weight, c[j].weight = c[j].weight, c[i].weight
+	}
+}
+
+//Reverses the order of the joins ranked within a random segment of the
+//weight ordering.
+type InversionMutator struct {
+	Mean, StdDev float64
+}
+
+func (p InversionMutator) Mutate(c Genotype, r *rand.Rand) {
+	take := normalCount(p.Mean, p.StdDev, r)
+	if take == 0 {
+		return
+	}
+	order := ranking(c)
+	for ; take > 0; take-- {
+		start, end := r.Intn(len(c)), r.Intn(len(c))
+		if start > end {
+			start, end = end, start
+		}
+		for ; start < end; start, end = start+1, end-1 {
+			i, j := order[start], order[end]
+			c[i].weight, c[j].weight = c[j].weight, c[i].weight
+			order[start], order[end] = j, i
+		}
+	}
+}
+
+//Moves a join to the front of the ordering, so it's tried before any
+//other, or to the back, with the same probability. Weights may end up
+//slightly out of [0, 1] when the extremes are already at the bounds.
+type PromoteMutator struct {
+	Mean, StdDev float64
+}
+
+func (p PromoteMutator) Mutate(c Genotype, r *rand.Rand) {
+	for take := normalCount(p.Mean, p.StdDev, r); take > 0; take-- {
+		min, max := c[0].weight, c[0].weight
+		for _, wj := range c {
+			if wj.weight < min {
+				min = wj.weight
+			} else if wj.weight > max {
+				max = wj.weight
+			}
+		}
+		//halfway to the bounds, or right past the extremes when there's
+		//no room left, so the join never ties with another one
+		i := r.Intn(len(c))
+		if r.Intn(2) == 0 {
+			c[i].weight = min / 2
+			if c[i].weight >= min {
+				c[i].weight = math.Nextafter32(min, -1)
+			}
+		} else {
+			c[i].weight = (max + 1) / 2
+			if c[i].weight <= max {
+				c[i].weight = math.Nextafter32(max, 2)
+			}
+		}
+	}
+}
+
+//Applies every mutator in turn.
+type CompoundMutator []Mutator
+
+func (cm CompoundMutator) Mutate(g Genotype, r *rand.Rand) {
+	for _, m := range cm {
+		m(g, r)
+	}
+}
+
+var _ Mutator = GaussianWeightMutator{}.Mutate
+var _ Mutator = SwapMutator{}.Mutate
+var _ Mutator = InversionMutator{}.Mutate
+var _ Mutator = PromoteMutator{}.Mutate
+var _ Mutator = CompoundMutator{}.Mutate
@@ -0,0 +1,102 @@
+package guillotine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSwapAndInversionKeepWeights(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	mutators := map[string]Mutator{
+		"swap":      SwapMutator{Mean: 4, StdDev: 1}.Mutate,
+		"inversion": InversionMutator{Mean: 2, StdDev: 1}.Mutate,
+	}
+	for name, mutate := range mutators {
+		g := NewRandomGenotype(8, r)
+		before := sortedWeights(g)
+		mutate(g, r)
+		after := sortedWeights(g)
+		for i := range before {
+			if before[i] != after[i] {
+				t.Fatalf("Expected %s to only reorder weights, got %v from %v", name, after, before)
+			}
+		}
+	}
+}
+
+func TestInversionMutator(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	g := NewRandomGenotype(6, r)
+	order := ranking(g)
+	InversionMutator{Mean: 1}.Mutate(g, r)
+	//a single inversion reverses a contiguous segment of the ranking
+	inverted := ranking(g)
+	start, end := 0, len(order)-1
+	for start < len(order) && order[start] == inverted[start] {
+		start++
+	}
+	for end >= 0 && order[end] == inverted[end] {
+		end--
+	}
+	for i := start; i <= end; i++ {
+		if inverted[i] != order[start+end-i] {
+			t.Fatalf("Expected a reversed segment, got %v from %v", inverted, order)
+		}
+	}
+}
+
+func TestPromoteMutator(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 20; k++ {
+		g := NewRandomGenotype(6, r)
+		before := g.copy()
+		PromoteMutator{Mean: 1}.Mutate(g, r)
+		order := ranking(g)
+		first, last := order[0], order[len(order)-1]
+		changed := -1
+		for i := range g {
+			if g[i].weight != before[i].weight {
+				changed = i
+			}
+		}
+		if changed != -1 && changed != first && changed != last {
+			t.Fatalf("Expected the mutated join to move to the front or back")
+		}
+	}
+}
+
+func TestPromoteMutatorAtBounds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 20; k++ {
+		g := NewRandomGenotype(6, r)
+		g[0].weight, g[1].weight, g[2].weight, g[3].weight = 0, 0, 1, 1
+		before := g.copy()
+		PromoteMutator{Mean: 1}.Mutate(g, r)
+		for i := range g {
+			if g[i].weight == before[i].weight {
+				continue
+			}
+			front, back := true, true
+			for j := range g {
+				if j != i {
+					front = front && g[i].weight < g[j].weight
+					back = back && g[i].weight > g[j].weight
+				}
+			}
+			if !front && !back {
+				t.Fatalf("Expected join %d to move strictly to the front or back, got %v", i, g[i].weight)
+			}
+		}
+	}
+}
+
+func TestCompoundMutator(t *testing.T) {
+	var calls []string
+	record := func(name string) Mutator {
+		return func(Genotype, *rand.Rand) { calls = append(calls, name) }
+	}
+	CompoundMutator{record("a"), record("b")}.Mutate(nil, nil)
+	if len(calls) != 2 || calls[0] != "a" || calls[1] != "b" {
+		t.Errorf("Expected both mutators in order, got %v", calls)
+	}
+}
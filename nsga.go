@@ -0,0 +1,353 @@
+package guillotine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/rdarder/guillotine/evolve"
+)
+
+//Objective for multi objective runs, by name: area, height, cuts or
+//stages. All of them are minimized.
+func ParseObjective(name string) (Fitness, error) {
+	switch name {
+	case "area":
+		return (*LayoutTree).Area, nil
+	case "height":
+		return (*LayoutTree).Height, nil
+	case "cuts":
+		return (*LayoutTree).CutCount, nil
+	case "stages":
+		return (*LayoutTree).Stages, nil
+	}
+	return nil, fmt.Errorf("unknown objective %q", name)
+}
+
+//A layout on the Pareto front, with its value for every objective.
+type ParetoSolution struct {
+	Layout *LayoutTree
+	Values []uint
+}
+
+//Multi objective genetic algorithm, following NSGA-II: parents and
+//children compete together for the next generation, ranked first by
+//non dominated front and then by crowding distance, which favors
+//solutions far from their neighbours on the same front.
+//
+//Runs on the same engine as the GeneticAlgorithm, so they take the
+//same Stop conditions and Observers. The fitness of every individual
+//is its front, 0 for non dominated ones, and populations are sorted by
+//crowded comparison. See Front for the layouts of a population.
+type NSGA2 struct {
+	Spec           *CutSpec
+	Objectives     []Fitness
+	Mutator        Mutator
+	Breeder        Crossover
+	R              *rand.Rand
+	PopulationSize uint
+	Generations    uint
+	//Amount of goroutines evaluating the population, see GeneticAlgorithm
+	Workers int
+	//Optional, genotypes included in the initial population
+	Seeds []Genotype
+	//Optional, notified of the progress of every generation
+	Observer Observer
+	//When to end a run, defaults to MaxGenerations(Generations)
+	Stop StopCondition
+	//Objective values of the last ranked population, in its order
+	values [][]uint
+}
+
+type ParetoResult struct {
+	Generations uint
+	Evaluations uint
+	//Non dominated layouts, one for each combination of objective
+	//values, sorted by their values.
+	Front   []ParetoSolution
+	Elapsed time.Duration
+	//Why the run ended, see StopCondition
+	Reason string
+}
+
+type moIndividual struct {
+	genotype Genotype
+	values   []uint
+	rank     int
+	crowding float64
+}
+
+//Whether a is no worse than b in every objective and better in one.
+func dominates(a, b []uint) bool {
+	better := false
+	for k := range a {
+		if a[k] > b[k] {
+			return false
+		} else if a[k] < b[k] {
+			better = true
+		}
+	}
+	return better
+}
+
+//Fast non dominated sort. Sets every individual's rank and returns the
+//fronts, from the non dominated one onwards.
+func nonDominatedSort(pop []*moIndividual) [][]*moIndividual {
+	dominated := make([][]int, len(pop))
+	counts := make([]int, len(pop))
+	var current []int
+	for p := range pop {
+		for q := range pop {
+			if dominates(pop[p].values, pop[q].values) {
+				dominated[p] = append(dominated[p], q)
+			} else if dominates(pop[q].values, pop[p].values) {
+				counts[p]++
+			}
+		}
+		if counts[p] == 0 {
+			current = append(current, p)
+		}
+	}
+	var fronts [][]*moIndividual
+	for rank := 0; len(current) > 0; rank++ {
+		front := make([]*moIndividual, len(current))
+		var next []int
+		for i, p := range current {
+			pop[p].rank = rank
+			front[i] = pop[p]
+			for _, q := range dominated[p] {
+				if counts[q]--; counts[q] == 0 {
+					next = append(next, q)
+				}
+			}
+		}
+		sort.Ints(next)
+		fronts = append(fronts, front)
+		current = next
+	}
+	return fronts
+}
+
+//Sets the crowding distance of every individual in the front: the sum
+//over objectives of the normalized distance between its neighbours.
+//Extremes get an infinite distance so they're always kept.
+func crowdingDistance(front []*moIndividual) {
+	for _, id := range front {
+		id.crowding = 0
+	}
+	if len(front) == 0 {
+		return
+	}
+	sorted := make([]*moIndividual, len(front))
+	copy(sorted, front)
+	for k := range front[0].values {
+		sort.SliceStable(sorted, func(a, b int) bool {
+			return sorted[a].values[k] < sorted[b].values[k]
+		})
+		last := len(sorted) - 1
+		sorted[0].crowding = math.Inf(1)
+		sorted[last].crowding = math.Inf(1)
+		spread := float64(sorted[last].values[k]) - float64(sorted[0].values[k])
+		if spread == 0 {
+			continue
+		}
+		for i := 1; i < last; i++ {
+			d := float64(sorted[i+1].values[k]) - float64(sorted[i-1].values[k])
+			sorted[i].crowding += d / spread
+		}
+	}
+}
+
+//Crowded comparison: lower front first, then less crowded.
+func crowdedLess(a, b *moIndividual) bool {
+	if a.rank != b.rank {
+		return a.rank < b.rank
+	}
+	return a.crowding > b.crowding
+}
+
+//Objective values of every genotype, in the same order.
+func (n *NSGA2) evaluate(pop Population) [][]uint {
+	values := make([][]uint, len(pop))
+	evolve.ParallelRange(len(pop), n.Workers, func(start, end int) {
+		for i := start; i < end; i++ {
+			lt := GetPhenotype(n.Spec, pop[i])
+			values[i] = make([]uint, len(n.Objectives))
+			for k, objective := range n.Objectives {
+				values[i][k] = objective(lt)
+			}
+		}
+	})
+	return values
+}
+
+//Keeps the best PopulationSize individuals by crowded comparison.
+//Whole fronts are taken while they fit, the last one by crowding.
+func (n *NSGA2) survivors(pop []*moIndividual) []*moIndividual {
+	next := make([]*moIndividual, 0, n.PopulationSize)
+	for _, front := range nonDominatedSort(pop) {
+		crowdingDistance(front)
+		if room := int(n.PopulationSize) - len(next); len(front) > room {
+			sort.SliceStable(front, func(a, b int) bool {
+				return front[a].crowding > front[b].crowding
+			})
+			next = append(next, front[:room]...)
+			break
+		}
+		next = append(next, front...)
+	}
+	return next
+}
+
+//Survivors of pop, given its objective values, sorted by crowded
+//comparison with their front as fitness.
+func (n *NSGA2) rank(pop Population, values [][]uint) *RankedPopulation {
+	ids := make([]*moIndividual, len(pop))
+	for i := range pop {
+		ids[i] = &moIndividual{genotype: pop[i], values: values[i]}
+	}
+	ids = n.survivors(ids)
+	sort.SliceStable(ids, func(a, b int) bool {
+		return crowdedLess(ids[a], ids[b])
+	})
+	rp := &RankedPopulation{Pop: make(Population, len(ids)), Fitnesses: make([]uint, len(ids))}
+	n.values = make([][]uint, len(ids))
+	for i, id := range ids {
+		rp.Pop[i], rp.Fitnesses[i], n.values[i] = id.genotype, uint(id.rank), id.values
+	}
+	return rp
+}
+
+//Parents are picked by binary tournaments on their position, as rp is
+//sorted by crowded comparison.
+func crowdedView(rp *RankedPopulation) (*RankedPopulation, []int) {
+	view := &RankedPopulation{Pop: rp.Pop, Fitnesses: make([]uint, len(rp.Pop))}
+	index := make([]int, len(rp.Pop))
+	for i := range index {
+		view.Fitnesses[i], index[i] = uint(i), i
+	}
+	return view, index
+}
+
+//Children of the population compete with it, keeping the objective
+//values of the parents.
+func (n *NSGA2) next(e *engine, p *Progress) *RankedPopulation {
+	parents := p.Population
+	children := e.Next(parents)
+	pop := append(append(Population{}, parents.Pop...), children...)
+	values := append(append([][]uint{}, n.values...), n.evaluate(children)...)
+	return n.rank(pop, values)
+}
+
+func (n *NSGA2) engine() *engine {
+	nboards := uint16(len(n.Spec.Boards))
+	e := &engine{
+		Random: func(r *rand.Rand) Genotype {
+			return NewRandomGenotype(nboards, r)
+		},
+		Decode: func(g Genotype) *LayoutTree {
+			return GetPhenotype(n.Spec, g)
+		},
+		Ranking: func(pop []Genotype) *RankedPopulation {
+			return n.rank(pop, n.evaluate(pop))
+		},
+		Mutator:         n.Mutator,
+		Breeder:         n.Breeder,
+		SelectorBuilder: NewTournamentSelectorBuilder(2, 1, n.R, true),
+		SelectionView:   crowdedView,
+		R:               n.R,
+		PopulationSize:  n.PopulationSize,
+		Generations:     n.Generations,
+		Workers:         n.Workers,
+		Seeds:           n.Seeds,
+		Observer:        n.Observer,
+		Stop:            n.Stop,
+	}
+	e.NextGeneration = func(p *Progress) *RankedPopulation {
+		return n.next(e, p)
+	}
+	return e
+}
+
+//Layouts of the first front of a population ranked by the NSGA2, one
+//for each combination of objective values.
+func (n *NSGA2) Front(rp *RankedPopulation) []ParetoSolution {
+	var first Population
+	for i, g := range rp.Pop {
+		if rp.Fitnesses[i] == 0 {
+			first = append(first, g)
+		}
+	}
+	values := n.evaluate(first)
+	order := make([]int, len(first))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		va, vb := values[order[a]], values[order[b]]
+		for k := range va {
+			if va[k] != vb[k] {
+				return va[k] < vb[k]
+			}
+		}
+		return false
+	})
+	var solutions []ParetoSolution
+	for i, o := range order {
+		if i > 0 && equalValues(values[order[i-1]], values[o]) {
+			continue
+		}
+		solutions = append(solutions, ParetoSolution{GetPhenotype(n.Spec, first[o]), values[o]})
+	}
+	return solutions
+}
+
+func equalValues(a, b []uint) bool {
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *NSGA2) run(ctx context.Context, stop StopCondition) (*ParetoResult, error) {
+	if len(n.Objectives) == 0 {
+		return nil, fmt.Errorf("no objectives")
+	}
+	if n.PopulationSize < 2 {
+		return nil, fmt.Errorf("binary tournaments need a population of at least 2")
+	}
+	start := time.Now()
+	e := n.engine()
+	result, err := e.Evolve(ctx, e.First(start), start, stop)
+	return &ParetoResult{
+		Generations: result.Generations,
+		Evaluations: result.Evaluations,
+		Front:       n.Front(result.Population),
+		Elapsed:     time.Since(start),
+		Reason:      result.Reason,
+	}, err
+}
+
+func (n *NSGA2) stopCondition() StopCondition {
+	if n.Stop != nil {
+		return n.Stop
+	}
+	return MaxGenerations(n.Generations)
+}
+
+//Runs until n.Stop, or n.Generations if there's no Stop condition, or
+//until the context is done.
+func (n *NSGA2) RunContext(ctx context.Context) (*ParetoResult, error) {
+	return n.run(ctx, n.stopCondition())
+}
+
+//Like RunContext, but also stops when the next generation is expected
+//to end past the time limit.
+func (n *NSGA2) TimeBoundedRunContext(ctx context.Context, limit time.Duration) (*ParetoResult, error) {
+	return n.run(ctx, AnyOf(n.stopCondition(), WallClock(limit)))
+}
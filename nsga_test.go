@@ -0,0 +1,92 @@
+package guillotine
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func moPopulation(values ...[]uint) []*moIndividual {
+	pop := make([]*moIndividual, len(values))
+	for i, v := range values {
+		pop[i] = &moIndividual{values: v}
+	}
+	return pop
+}
+
+func TestNonDominatedSort(t *testing.T) {
+	pop := moPopulation(
+		[]uint{1, 5}, []uint{2, 2}, []uint{5, 1},
+		[]uint{3, 3}, []uint{2, 6},
+		[]uint{4, 7},
+	)
+	fronts := nonDominatedSort(pop)
+	if len(fronts) != 3 {
+		t.Fatalf("Expected 3 fronts, got %d", len(fronts))
+	}
+	expected := []int{0, 0, 0, 1, 1, 2}
+	for i, id := range pop {
+		if id.rank != expected[i] {
+			t.Errorf("Expected %v on front %d, got %d", id.values, expected[i], id.rank)
+		}
+	}
+}
+
+func TestCrowdingDistance(t *testing.T) {
+	front := moPopulation([]uint{1, 9}, []uint{2, 8}, []uint{5, 5}, []uint{9, 1})
+	crowdingDistance(front)
+	if !math.IsInf(front[0].crowding, 1) || !math.IsInf(front[3].crowding, 1) {
+		t.Errorf("Expected extremes to be kept, got %v and %v", front[0].crowding, front[3].crowding)
+	}
+	if front[1].crowding >= front[2].crowding {
+		t.Errorf("Expected the crowded solution to have a lower distance, got %v and %v",
+			front[1].crowding, front[2].crowding)
+	}
+}
+
+func TestNSGA2Front(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ga := testGA(r, 8)
+	nsga := &NSGA2{
+		Spec:           ga.Spec,
+		Objectives:     []Fitness{(*LayoutTree).Area, (*LayoutTree).CutCount, (*LayoutTree).Stages},
+		Mutator:        ga.Mutator,
+		Breeder:        ga.Breeder,
+		R:              r,
+		PopulationSize: 20,
+		Stop:           EvaluationBudget(200),
+	}
+	var generations uint
+	nsga.Observer = func(p *Progress) {
+		generations = p.Generation
+		if p.Best != 0 || len(nsga.Front(p.Population)) == 0 {
+			t.Fatalf("Expected the population to lead with the first front, got %v", p.Population.Fitnesses)
+		}
+		for i := 1; i < len(p.Population.Fitnesses); i++ {
+			if p.Population.Fitnesses[i] < p.Population.Fitnesses[i-1] {
+				t.Fatalf("Expected the population sorted by front, got %v", p.Population.Fitnesses)
+			}
+		}
+	}
+	result, err := nsga.RunContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Front) == 0 || result.Evaluations != 200 || generations != 10 {
+		t.Fatalf("Expected a front after 10 generations of 20 evaluations, got %d solutions after %d",
+			len(result.Front), result.Evaluations)
+	}
+	for i, s := range result.Front {
+		for k, objective := range nsga.Objectives {
+			if v := objective(s.Layout); v != s.Values[k] {
+				t.Errorf("Expected objective %d of solution %d to be %d, got %d", k, i, v, s.Values[k])
+			}
+		}
+		for _, other := range result.Front {
+			if dominates(other.Values, s.Values) {
+				t.Errorf("Expected a non dominated front, %v dominates %v", other.Values, s.Values)
+			}
+		}
+	}
+}
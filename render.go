@@ -0,0 +1,148 @@
+package guillotine
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+)
+
+var (
+	backgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	borderColor     = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	wasteColor      = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	partColors      = []color.Color{
+		color.RGBA{0x4e, 0x79, 0xa7, 0xff},
+		color.RGBA{0xf2, 0x8e, 0x2b, 0xff},
+		color.RGBA{0xe1, 0x57, 0x59, 0xff},
+		color.RGBA{0x76, 0xb7, 0xb2, 0xff},
+		color.RGBA{0x59, 0xa1, 0x4f, 0xff},
+		color.RGBA{0xed, 0xc9, 0x48, 0xff},
+		color.RGBA{0xb0, 0x7a, 0xa1, 0xff},
+		color.RGBA{0xff, 0x9d, 0xa7, 0xff},
+		color.RGBA{0x9c, 0x75, 0x5f, 0xff},
+		color.RGBA{0xba, 0xb0, 0xac, 0xff},
+	}
+)
+
+//Palette shared by every rendered image, so gif frames don't need
+//their own local color tables.
+var renderPalette = append(color.Palette{backgroundColor, borderColor, wasteColor},
+	partColors...)
+
+const (
+	backgroundIndex = iota
+	borderIndex
+	wasteIndex
+	firstPartIndex
+)
+
+//Scale that makes the longest side of the sheet size pixels long.
+func (d *Drawing) FitScale(size int) float64 {
+	longest := d.Sheet.Width
+	if d.Sheet.Height > longest {
+		longest = d.Sheet.Height
+	}
+	if longest == 0 {
+		return 1
+	}
+	return float64(size) / float64(longest)
+}
+
+func scaled(v uint, scale float64) int {
+	return int(math.Floor(float64(v)*scale + 0.5))
+}
+
+//Rasterizes the drawing, scale is the amount of pixels per board unit.
+//Waste is painted gray, and each part gets a color from a fixed
+//cycling palette, surrounded by a 1px border.
+func (d *Drawing) Image(scale float64) *image.Paletted {
+	width, height := scaled(d.Sheet.Width, scale), scaled(d.Sheet.Height, scale)
+	img := image.NewPaletted(image.Rect(0, 0, width+1, height+1), renderPalette)
+	fillRect(img, 0, 0, width, height, wasteIndex)
+	strokeRect(img, 0, 0, width, height, borderIndex)
+	for i, box := range d.Boxes {
+		x0, y0 := scaled(box.X, scale), scaled(box.Y, scale)
+		x1, y1 := scaled(box.X+box.Width, scale), scaled(box.Y+box.Height, scale)
+		fillRect(img, x0, y0, x1, y1, uint8(firstPartIndex+i%len(partColors)))
+		strokeRect(img, x0, y0, x1, y1, borderIndex)
+	}
+	return img
+}
+
+func (d *Drawing) WritePNG(w io.Writer, scale float64) error {
+	return png.Encode(w, d.Image(scale))
+}
+
+func fillRect(img *image.Paletted, x0, y0, x1, y1 int, c uint8) {
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			img.SetColorIndex(x, y, c)
+		}
+	}
+}
+
+func strokeRect(img *image.Paletted, x0, y0, x1, y1 int, c uint8) {
+	for x := x0; x <= x1; x++ {
+		img.SetColorIndex(x, y0, c)
+		img.SetColorIndex(x, y1, c)
+	}
+	for y := y0; y <= y1; y++ {
+		img.SetColorIndex(x0, y, c)
+		img.SetColorIndex(x1, y, c)
+	}
+}
+
+//Records a sequence of layouts as frames of an animated gif.
+//The GeneticAlgorithm feeds it with the best layout of each
+//generation when set as its Recorder.
+type GifRecorder struct {
+	//Pixels per board unit
+	Scale float64
+	//Delay between frames, in 100ths of a second
+	Delay  int
+	frames []*image.Paletted
+}
+
+func NewGifRecorder(scale float64, delay int) *GifRecorder {
+	return &GifRecorder{Scale: scale, Delay: delay}
+}
+
+func (gr *GifRecorder) Record(lt *LayoutTree) {
+	gr.frames = append(gr.frames, NewDrawer(lt).Draw().Image(gr.Scale))
+}
+
+func (gr *GifRecorder) Len() int { return len(gr.frames) }
+
+//Writes all the recorded frames. Sheet sizes change as the layout
+//evolves, so every frame is padded to the largest one.
+func (gr *GifRecorder) Encode(w io.Writer) error {
+	var bounds image.Rectangle
+	for _, frame := range gr.frames {
+		bounds = bounds.Union(frame.Bounds())
+	}
+	anim := &gif.GIF{
+		Image: make([]*image.Paletted, len(gr.frames)),
+		Delay: make([]int, len(gr.frames)),
+		Config: image.Config{
+			ColorModel: renderPalette,
+			Width:      bounds.Dx(),
+			Height:     bounds.Dy(),
+		},
+	}
+	for i, frame := range gr.frames {
+		padded := image.NewPaletted(bounds, renderPalette)
+		for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+			copy(padded.Pix[padded.PixOffset(0, y):], frame.Pix[frame.PixOffset(0, y):frame.PixOffset(frame.Rect.Max.X, y)])
+		}
+		anim.Image[i] = padded
+		anim.Delay[i] = gr.Delay
+	}
+	//hold the final layout on screen a bit longer
+	if n := len(anim.Delay); n > 0 {
+		anim.Delay[n-1] = 4 * gr.Delay
+	}
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,67 @@
+package guillotine
+
+import (
+	"bytes"
+	"image/gif"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+func randomLayout(r *rand.Rand, nboards int) *LayoutTree {
+	spec := NewRandomSpec(nboards, 40, 50, r, false)
+	return GetPhenotype(spec, NewRandomGenotype(uint16(nboards), r))
+}
+
+func TestWritePNG(t *testing.T) {
+	drawing := NewDrawer(randomLayout(rand.New(rand.NewSource(1)), 8)).Draw()
+	var b bytes.Buffer
+	if err := drawing.WritePNG(&b, 2); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	width, height := 2*int(drawing.Sheet.Width)+1, 2*int(drawing.Sheet.Height)+1
+	if size := img.Bounds().Size(); size.X != width || size.Y != height {
+		t.Errorf("Expected a %dx%d image, got %v", width, height, size)
+	}
+}
+
+func TestGifRecorder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	recorder := NewGifRecorder(2, 10)
+	var width, height int
+	for i := 0; i < 3; i++ {
+		lt := randomLayout(r, 4+2*i)
+		recorder.Record(lt)
+		sheet := NewDrawer(lt).Draw().Sheet
+		if w := 2*int(sheet.Width) + 1; w > width {
+			width = w
+		}
+		if h := 2*int(sheet.Height) + 1; h > height {
+			height = h
+		}
+	}
+	var b bytes.Buffer
+	if err := recorder.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 3 || anim.Config.Width != width || anim.Config.Height != height {
+		t.Errorf("Expected 3 frames of %dx%d, got %d of %dx%d",
+			width, height, len(anim.Image), anim.Config.Width, anim.Config.Height)
+	}
+	for _, frame := range anim.Image {
+		if size := frame.Bounds().Size(); size.X != width || size.Y != height {
+			t.Errorf("Expected frames padded to %dx%d, got %v", width, height, size)
+		}
+	}
+	if anim.Delay[0] != 10 || anim.Delay[2] != 40 {
+		t.Errorf("Expected the last frame to be held longer, got delays %v", anim.Delay)
+	}
+}
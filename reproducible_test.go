@@ -0,0 +1,75 @@
+package guillotine
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+var reproducibleBreeders = map[string]Crossover{
+	"uniform":  UniformCrossover,
+	"onepoint": OnePointCrossover,
+	"twopoint": TwoPointCrossover,
+}
+
+// Runs testGA on its own seeded source, for a fixed amount of generations.
+func seededRun(t *testing.T, seed int64, breeder string, workers int) *RunResult {
+	src := NewSource(seed)
+	ga := testGA(rand.New(src), 10)
+	ga.Breeder = reproducibleBreeders[breeder]
+	ga.Source = src
+	ga.Workers = workers
+	ga.Generations = 30
+	result, err := ga.RunContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestReproducibleRuns(t *testing.T) {
+	for name := range reproducibleBreeders {
+		first := seededRun(t, 7, name, 1)
+		for _, workers := range []int{1, 4} {
+			again := seededRun(t, 7, name, workers)
+			if again.Fitness != first.Fitness || again.Evaluations != first.Evaluations {
+				t.Errorf("Expected %s runs with the same seed to match, got fitness %d and %d",
+					name, first.Fitness, again.Fitness)
+			}
+			if again.Layout.Canonical() != first.Layout.Canonical() {
+				t.Errorf("Expected %s runs with the same seed to find the same layout", name)
+			}
+		}
+		if first.Seed != 7 {
+			t.Errorf("Expected the run seed in the result, got %d", first.Seed)
+		}
+	}
+}
+
+// Outputs of known seeds. A change here means runs are no longer
+// repeatable across versions, update them only when that's intended.
+func TestGoldenRuns(t *testing.T) {
+	golden := []struct {
+		seed    int64
+		breeder string
+		fitness uint
+		hash    uint64
+	}{
+		{1, "uniform", 2542, 0x2a13d73dce46109a},
+		{1, "onepoint", 2542, 0x8c2b7db2426faba},
+		{1, "twopoint", 2418, 0xf9c7df83b29ad60b},
+		{42, "uniform", 2436, 0x31e1d80bfceeca07},
+		{42, "onepoint", 2900, 0xbf816984fd3564a9},
+		{42, "twopoint", 2622, 0x6ac2be917b29ce3e},
+		{2024, "uniform", 2436, 0x829a7c24e46d0dc5},
+		{2024, "onepoint", 2610, 0x7bdefa6fe87a24c7},
+		{2024, "twopoint", 2340, 0x26c41df859743465},
+	}
+	for _, g := range golden {
+		result := seededRun(t, g.seed, g.breeder, 1)
+		if result.Fitness != g.fitness || result.Layout.Hash() != g.hash {
+			t.Errorf("Expected seed %d with %s crossover to yield %d/%#x, got %d/%#x",
+				g.seed, g.breeder, g.fitness, g.hash, result.Fitness, result.Layout.Hash())
+		}
+	}
+}
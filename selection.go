@@ -0,0 +1,37 @@
+package guillotine
+
+import (
+	"math/rand"
+
+	"github.com/rdarder/guillotine/evolve"
+)
+
+//Selectors for ranked populations of genotypes, see the evolve package
+//for their details.
+
+//Fitness proportional selection.
+func NewRouletteSelectorBuilder(r *rand.Rand, min bool) SelectorBuilder {
+	return evolve.NewRouletteSelectorBuilder[Genotype, uint](r, min)
+}
+
+//Linear ranking: the fittest individual is picked with probability
+//pressure/n and the least fit with (2-pressure)/n, pressure in [1, 2].
+func NewLinearRankSelectorBuilder(pressure float64, r *rand.Rand, min bool) SelectorBuilder {
+	return evolve.NewLinearRankSelectorBuilder[Genotype, uint](pressure, r, min)
+}
+
+//Stochastic universal sampling: a single spin picks a whole batch of
+//parents, with the same expectations as roulette selection but less
+//variance.
+type SUSSelector = evolve.SUSSelector[Genotype, uint]
+
+func NewSUSSelectorBuilder(r *rand.Rand, min bool) SelectorBuilder {
+	return evolve.NewSUSSelectorBuilder[Genotype, uint](r, min)
+}
+
+//Picks uniformly among the fittest fraction of the population.
+type TruncationSelector = evolve.TruncationSelector[Genotype, uint]
+
+func NewTruncationSelectorBuilder(fraction float64, r *rand.Rand, min bool) SelectorBuilder {
+	return evolve.NewTruncationSelectorBuilder[Genotype, uint](fraction, r, min)
+}
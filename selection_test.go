@@ -0,0 +1,109 @@
+package guillotine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+//Population with fitnesses 1..n, genotypes remember their fitness
+//in their first weight.
+func selectionPopulation(n int) *RankedPopulation {
+	rp := &RankedPopulation{Pop: make(Population, n), Fitnesses: make([]uint, n)}
+	for i := range rp.Pop {
+		rp.Pop[i] = Genotype{{weight: float32(i + 1)}}
+		rp.Fitnesses[i] = uint(i + 1)
+	}
+	return rp
+}
+
+func meanSelected(builder SelectorBuilder, rp *RankedPopulation, samples int) float64 {
+	selector := builder(rp)
+	var sum float64
+	for i := 0; i < samples; i++ {
+		sum += float64(selector.Next()[0].weight)
+	}
+	return sum / float64(samples)
+}
+
+func TestSelectorsDirection(t *testing.T) {
+	rp := selectionPopulation(20)
+	builders := map[string]func(r *rand.Rand, min bool) SelectorBuilder{
+		"tournament": func(r *rand.Rand, min bool) SelectorBuilder {
+			return NewTournamentSelectorBuilder(3, 0.8, r, min)
+		},
+		"roulette": NewRouletteSelectorBuilder,
+		"rank": func(r *rand.Rand, min bool) SelectorBuilder {
+			return NewLinearRankSelectorBuilder(1.8, r, min)
+		},
+		"sus": NewSUSSelectorBuilder,
+		"truncation": func(r *rand.Rand, min bool) SelectorBuilder {
+			return NewTruncationSelectorBuilder(0.25, r, min)
+		},
+	}
+	for name, builder := range builders {
+		r := rand.New(rand.NewSource(1))
+		if mean := meanSelected(builder(r, true), rp, 4000); mean >= 8 {
+			t.Errorf("Expected %s to favor low fitness when minimizing, got mean %v", name, mean)
+		}
+		if mean := meanSelected(builder(r, false), rp, 4000); mean <= 13 {
+			t.Errorf("Expected %s to favor high fitness when maximizing, got mean %v", name, mean)
+		}
+	}
+}
+
+func TestTournamentMaxWinner(t *testing.T) {
+	rp := selectionPopulation(10)
+	r := rand.New(rand.NewSource(1))
+	//with p=1 the best candidate always wins the tournament, replayed
+	//here from an equally seeded source
+	replay := rand.New(rand.NewSource(1))
+	selector := NewTournamentSelectorBuilder(10, 1, r, false)(rp)
+	for i := 0; i < 100; i++ {
+		var best float32
+		for k := 0; k < 10; k++ {
+			if w := rp.Pop[replay.Intn(10)][0].weight; w > best {
+				best = w
+			}
+		}
+		replay.Float32()
+		if f := selector.Next()[0].weight; f != best {
+			t.Fatalf("Expected the fittest candidate %v, got %v", best, f)
+		}
+	}
+}
+
+func TestTournamentBounds(t *testing.T) {
+	rp := selectionPopulation(10)
+	r := rand.New(rand.NewSource(1))
+	invalid := map[string]func(){
+		"size 0":    func() { NewTournamentSelectorBuilder(0, 0.5, r, true) },
+		"p 0":       func() { NewTournamentSelectorBuilder(2, 0, r, true) },
+		"p above 1": func() { NewTournamentSelectorBuilder(2, 1.5, r, true) },
+		"size 11":   func() { NewTournamentSelectorBuilder(11, 0.5, r, true)(rp) },
+	}
+	for name, f := range invalid {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a tournament with %s to be rejected", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestTruncationBounds(t *testing.T) {
+	rp := selectionPopulation(10)
+	r := rand.New(rand.NewSource(1))
+	min := NewTruncationSelectorBuilder(0.3, r, true)(rp)
+	max := NewTruncationSelectorBuilder(0.3, r, false)(rp)
+	for i := 0; i < 100; i++ {
+		if f := min.Next()[0].weight; f > 3 {
+			t.Fatalf("Expected one of the 3 lowest fitnesses, got %v", f)
+		}
+		if f := max.Next()[0].weight; f < 8 {
+			t.Fatalf("Expected one of the 3 highest fitnesses, got %v", f)
+		}
+	}
+}
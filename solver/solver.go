@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/rdarder/guillotine"
+	"io"
 	"log"
+	"math"
 	"math/rand"
 	"os"
+	"os/signal"
+	"reflect"
+	"runtime"
 	"runtime/pprof"
+	"strings"
 	"time"
 )
 
@@ -25,14 +32,81 @@ func main() {
 	var area = flag.Int("area", 2000, "Target total area")
 	var maxWidth = flag.Int("maxWidth", 0, "sheet max width")
 	var psel = flag.Float64("psel", 0.8, "Tournament selection probability")
-	var cx = flag.String("crossover", "uniform", "Crossover strategy")
+	var selection = flag.String("selection", "tournament", "Selection strategy: tournament, roulette, rank, sus or truncation")
+	var pressure = flag.Float64("pressure", 1.7, "Linear rank selection pressure, between 1 and 2")
+	var truncation = flag.Float64("truncation", 0.3, "Fraction of the population eligible on truncation selection")
+	var cx = flag.String("crossover", "uniform", "Crossover strategy: uniform, onepoint, twopoint, blend, arithmetic, sbx or order")
+	var blendAlpha = flag.Float64("blendAlpha", 0.5, "Interval extension of the blend crossover")
+	var sbxEta = flag.Float64("sbxEta", 2, "Distribution index of the sbx crossover")
 	var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 	var weightMutateMean = flag.Float64("weightMutateMean", 10,
 		"Mean number of gene weights to be mutated on each individual")
 	var configMutateMean = flag.Float64("configMutateMean", 10,
 		"Mean number of pick configs to be mutated on each individual")
+	var gaussianMutateMean = flag.Float64("gaussianMutateMean", 0,
+		"Mean number of gene weights to be shifted by a gaussian perturbation on each individual")
+	var gaussianSigma = flag.Float64("gaussianSigma", 0.1, "Standard deviation of gaussian weight perturbations")
+	var swapMutateMean = flag.Float64("swapMutateMean", 0,
+		"Mean number of gene weight swaps on each individual")
+	var inversionMutateMean = flag.Float64("inversionMutateMean", 0,
+		"Mean number of weight ordering segments to be inverted on each individual")
+	var promoteMutateMean = flag.Float64("promoteMutateMean", 0,
+		"Mean number of joins to be moved to the front or back of the ordering on each individual")
+	var adaptive = flag.Bool("adaptive", false,
+		"Adapt mutation means during the run with the 1/5th success rule, starting from the given ones")
+	var sharingRadius = flag.Float64("sharingRadius", 0,
+		"Genotype distance below which individuals share their fitness on selection, 0 disables sharing")
+	var sharingAlpha = flag.Float64("sharingAlpha", 1, "Shape of the fitness sharing function")
+	var crowding = flag.Bool("crowding", false, "Replace parents by their closest children with deterministic crowding")
+	var immigrantThreshold = flag.Float64("immigrantThreshold", 0,
+		"Genotype diversity below which random immigrants are injected, 0 disables immigrants")
+	var steadyState = flag.String("steadyState", "",
+		"Replace individuals one child at a time, the worst one or a tournament loser, empty breeds whole generations")
+	var replacementSize = flag.Int("replacementSize", 3, "Tournament size picking the individual replaced on steady state runs")
+	var immigrantFraction = flag.Float64("immigrantFraction", 0.2, "Fraction of the population replaced by random immigrants")
 	var generations = flag.Int("generations", 10, "Number of generations")
+	var engine = flag.String("engine", "ga", "Search engine: ga, brkga or annealing")
+	var cooling = flag.String("cooling", "geometric", "Annealing cooling schedule: geometric, linear or logarithmic")
+	var coolingRate = flag.Float64("coolingRate", 0.95, "Temperature factor per level of the geometric cooling")
+	var temperature = flag.Float64("temperature", 0, "Initial annealing temperature, 0 estimates one")
+	var moves = flag.Int("moves", 100, "Neighbours tried on each annealing level")
+	var brkgaElite = flag.Float64("brkgaElite", 0.2, "Fraction of the population kept as elite by brkga")
+	var brkgaMutants = flag.Float64("brkgaMutants", 0.15, "Fraction of the population replaced by random genotypes by brkga")
+	var brkgaRho = flag.Float64("brkgaRho", 0.7, "Probability of inheriting each gene from the elite parent on brkga")
+	var restarts = flag.Int("restarts", 0,
+		"Times annealing starts over from the best layout, splitting the generations across schedules")
 	var seed = flag.Int64("seed", time.Now().Unix(), "Random seed for repeatable runs")
+	var pngOut = flag.String("png", "", "write the best layout as a png image to file")
+	var gifOut = flag.String("gif", "", "write the best layout of each generation as an animated gif to file")
+	var dxfOut = flag.String("dxf", "", "write the best layout and its cut lines as a DXF drawing to file")
+	var dxfOrigin = flag.String("dxfOrigin", "bottomleft", "sheet corner at the DXF origin: bottomleft, topleft, bottomright or topright")
+	var dxfUnits = flag.String("dxfUnits", "mm", "DXF drawing units, advisory as R12 readers may ignore them: none, mm, cm, m, in or ft")
+	var workers = flag.Int("workers", runtime.NumCPU(), "Number of goroutines evaluating the population")
+	var statsOut = flag.String("stats", "", "write per generation statistics to file")
+	var statsFormat = flag.String("statsFormat", "csv", "Statistics file format: csv or jsonl")
+	var benchmarkFile = flag.String("benchmark", "", "solve an instance from a benchmark file instead of a random spec")
+	var benchmarkFormat = flag.String("format", "strip", "Benchmark file format: gcut, ngcut, cgcut or strip")
+	var problem = flag.Int("problem", 1, "Problem number within benchmark files with several problems")
+	var bestKnownFile = flag.String("bestKnown", "", "file with lines of benchmark name and best known height, for strip files without one")
+	var cacheSize = flag.Int("cache", 0, "Number of fitnesses remembered across generations, 0 disables the cache")
+	var stagnation = flag.Int("stagnation", 0, "stop after this many generations without improvement, 0 disables")
+	var evaluations = flag.Int("evaluations", 0, "stop after this many evaluations, 0 disables")
+	var timeLimit = flag.Duration("timeLimit", 0, "stop before the run exceeds this time, 0 disables")
+	var lowerBound = flag.Bool("lowerBound", true, "stop once a layout without waste is found")
+	var objectives = flag.String("objectives", "",
+		"comma separated objectives (area, height, cuts, stages) for a multi objective run that outputs the Pareto front")
+	var islands = flag.Int("islands", 1, "Number of populations evolving in parallel")
+	var migrationInterval = flag.Int("migrationInterval", 10, "Generations between migrations across islands")
+	var migrants = flag.Int("migrants", 2, "Number of individuals each island sends on migrations")
+	var topologyName = flag.String("topology", "ring", "Islands migration topology: ring, full or random")
+	var checkpointOut = flag.String("checkpoint", "", "periodically save the run state to file")
+	var checkpointEvery = flag.Int("checkpointEvery", 10, "Generations between checkpoints")
+	var resume = flag.String("resume", "", "resume the run saved on a checkpoint file, ignoring the spec flags")
+	var saveLayout = flag.String("saveLayout", "", "write the spec and best layout as json to file")
+	var seedLayouts = flag.String("seedLayouts", "", "comma separated files written by -saveLayout, included in the initial population")
+	var progress = flag.Bool("progress", false, "report the progress of each generation to stderr")
+	var ascii = flag.Int("ascii", 0, "print the best layout as text this many columns wide")
+	var imageSize = flag.Int("imageSize", 600, "size in pixels of the longest side of the sheet on rendered images")
 
 	flag.Parse()
 
@@ -53,56 +127,570 @@ func main() {
 		crossover = guillotine.OnePointCrossover
 	case "twopoint":
 		crossover = guillotine.TwoPointCrossover
+	case "blend":
+		crossover = guillotine.BlendCrossover{Alpha: *blendAlpha}.Crossover
+	case "arithmetic":
+		crossover = guillotine.ArithmeticCrossover
+	case "sbx":
+		if *sbxEta <= 0 {
+			log.Fatal("sbxEta must be positive")
+		}
+		crossover = guillotine.SBXCrossover{Eta: *sbxEta}.Crossover
+	case "order":
+		crossover = guillotine.OrderCrossover
 	default:
 		panic("Invalid option for crossover")
 	}
 
-	r := rand.New(rand.NewSource(*seed))
+	var checkpoint *guillotine.Checkpoint
+	var src *guillotine.Source
+	if *resume != "" {
+		var err error
+		if checkpoint, err = guillotine.LoadCheckpoint(*resume); err != nil {
+			log.Fatal(err)
+		}
+		src = checkpoint.Source()
+	} else {
+		src = guillotine.NewSource(*seed)
+	}
+	r := rand.New(src)
 	var width, height uint
-	var limitWidth bool
+	var spec *guillotine.CutSpec
+	//benchmarks are compared by height or value, random specs by area
+	var benchmark *guillotine.Benchmark
+
+	if checkpoint != nil {
+		spec = checkpoint.Spec
+		benchmark = checkpoint.Benchmark
+		width, height = idealSheet(spec)
+	} else if *benchmarkFile != "" {
+		benchmark = loadBenchmark(*benchmarkFile, *benchmarkFormat, *problem, *bestKnownFile)
+		spec = benchmark.Spec
+		width, height = idealSheet(spec)
+	} else {
+		var limitWidth bool
+		if *maxWidth == 0 {
+			width, height = guillotine.AreaDimensions(float64(*area), r)
+			limitWidth = false
+		} else {
+			width, height = guillotine.MaxWidthDimensions(*maxWidth, r)
+			limitWidth = true
+		}
+		spec = guillotine.NewRandomSpec(*nboards, width, height, r, limitWidth)
+	}
+	target := spec.TotalArea
+	evaluator := (*guillotine.LayoutTree).Area
+	if benchmark != nil {
+		evaluator = benchmark.Fitness()
+	}
+
+	newSelectorBuilder := func(r *rand.Rand) guillotine.SelectorBuilder {
+		switch *selection {
+		case "tournament":
+			if *psel <= 0 || *psel > 1 {
+				log.Fatalf("psel must be in (0, 1], got %v", *psel)
+			}
+			if *tsize < 1 || *tsize > *population {
+				log.Fatalf("tsize must be between 1 and the population size, got %d", *tsize)
+			}
+			return guillotine.NewTournamentSelectorBuilder(*tsize, float32(*psel), r, true)
+		case "roulette":
+			return guillotine.NewRouletteSelectorBuilder(r, true)
+		case "rank":
+			return guillotine.NewLinearRankSelectorBuilder(*pressure, r, true)
+		case "sus":
+			return guillotine.NewSUSSelectorBuilder(r, true)
+		case "truncation":
+			return guillotine.NewTruncationSelectorBuilder(*truncation, r, true)
+		}
+		log.Fatalf("invalid selection %q", *selection)
+		return nil
+	}
+	var cache *guillotine.FitnessCache
+	if *cacheSize > 0 {
+		cache = guillotine.NewFitnessCache(*cacheSize)
+	}
+	newGA := func(r *rand.Rand) *guillotine.GeneticAlgorithm {
+		ga := &guillotine.GeneticAlgorithm{
+			Spec:      spec,
+			Evaluator: evaluator,
+			Mutator: guillotine.CompoundWeightConfigMutator{
+				Weight: guillotine.NormalWeightMutator{
+					Mean:   *weightMutateMean,
+					StdDev: *weightMutateMean / 5,
+				},
+				Config: guillotine.NormalConfigMutator{
+					Mean:   *configMutateMean,
+					StdDev: *configMutateMean / 5,
+				},
+			}.Mutate,
+			Breeder:         crossover,
+			SelectorBuilder: newSelectorBuilder(r),
+			R:               r,
+			EliteSize:       uint(*eliteSize),
+			PopulationSize:  uint(*population),
+			Generations:     uint(*generations),
+			Workers:         *workers,
+			Cache:           cache,
+		}
+		if *adaptive {
+			mutator := guillotine.NewAdaptiveMutator(*weightMutateMean, *configMutateMean)
+			ga.Mutator, ga.Adapter = mutator.Mutate, mutator
+		}
+		mutators := guillotine.CompoundMutator{ga.Mutator}
+		if *gaussianMutateMean > 0 {
+			mutators = append(mutators, guillotine.GaussianWeightMutator{
+				Mean:   *gaussianMutateMean,
+				StdDev: *gaussianMutateMean / 5,
+				Sigma:  *gaussianSigma,
+			}.Mutate)
+		}
+		if *swapMutateMean > 0 {
+			mutators = append(mutators, guillotine.SwapMutator{
+				Mean:   *swapMutateMean,
+				StdDev: *swapMutateMean / 5,
+			}.Mutate)
+		}
+		if *inversionMutateMean > 0 {
+			mutators = append(mutators, guillotine.InversionMutator{
+				Mean:   *inversionMutateMean,
+				StdDev: *inversionMutateMean / 5,
+			}.Mutate)
+		}
+		if *promoteMutateMean > 0 {
+			mutators = append(mutators, guillotine.PromoteMutator{
+				Mean:   *promoteMutateMean,
+				StdDev: *promoteMutateMean / 5,
+			}.Mutate)
+		}
+		if len(mutators) > 1 {
+			ga.Mutator = mutators.Mutate
+		}
+		if *sharingRadius > 0 {
+			ga.Sharing = &guillotine.FitnessSharing{Radius: *sharingRadius, Alpha: *sharingAlpha}
+		}
+		if *immigrantThreshold > 0 {
+			ga.Immigrants = &guillotine.RandomImmigrants{Threshold: *immigrantThreshold, Fraction: *immigrantFraction}
+		}
+		switch *steadyState {
+		case "":
+		case "worst":
+			ga.SteadyState = &guillotine.SteadyState{}
+		case "tournament":
+			ga.SteadyState = &guillotine.SteadyState{Tournament: *replacementSize}
+		default:
+			log.Fatalf("invalid steadyState %q", *steadyState)
+		}
+		ga.Crowding = *crowding
+		if *engine == "brkga" {
+			ga.BRKGA = &guillotine.BRKGA{Elite: *brkgaElite, Mutants: *brkgaMutants, Rho: *brkgaRho}
+		}
+		return ga
+	}
+	stop := []guillotine.StopCondition{guillotine.MaxGenerations(uint(*generations))}
+	if *stagnation > 0 {
+		stop = append(stop, guillotine.Stagnation(uint(*stagnation)))
+	}
+	if *evaluations > 0 {
+		stop = append(stop, guillotine.EvaluationBudget(uint(*evaluations)))
+	}
+	if *timeLimit > 0 {
+		stop = append(stop, guillotine.WallClock(*timeLimit))
+	}
+	//multi objective fitnesses are fronts, so they only stop on budgets
+	budget := append([]guillotine.StopCondition{}, stop...)
+	if *lowerBound && benchmark != nil && !benchmark.Knapsack() {
+		stop = append(stop, guillotine.LowerBound(spec.HeightLowerBound()))
+	} else if *lowerBound && benchmark == nil {
+		stop = append(stop, guillotine.LowerBound(spec.AreaLowerBound()))
+	}
+	if benchmark != nil && benchmark.Target() > 0 {
+		stop = append(stop, guillotine.TargetFitness(benchmark.Target()))
+	}
+
+	var recorder *guillotine.GifRecorder
+	if *gifOut != "" {
+		//All frames share the scale of the ideal sheet
+		sheet := &guillotine.Drawing{Sheet: guillotine.Rect{Width: width, Height: height}}
+		recorder = guillotine.NewGifRecorder(sheet.FitScale(*imageSize), 10)
+	}
+	var collector *guillotine.StatsCollector
+	if *statsOut != "" {
+		if *statsFormat != "csv" && *statsFormat != "jsonl" {
+			log.Fatalf("invalid statsFormat %q", *statsFormat)
+		}
+		collector = guillotine.NewStatsCollector(spec)
+		if checkpoint != nil {
+			collector.Generations = checkpoint.Stats
+		}
+	}
+	writeStats := func() {
+		if *statsFormat == "jsonl" {
+			writeFile(*statsOut, collector.WriteJSONL)
+		} else {
+			writeFile(*statsOut, collector.WriteCSV)
+		}
+	}
+	observer := func(p *guillotine.Progress) {
+		if collector != nil {
+			collector.Observe(p)
+		}
+		if recorder != nil {
+			recorder.Record(p.Phenotype)
+		}
+		if *progress {
+			fmt.Fprintf(os.Stderr, "generation %d: best %d, mean %.1f, %v\n",
+				p.Generation, p.Best, p.Mean, p.Elapsed)
+		}
+	}
+
+	if *objectives != "" {
+		if *islands > 1 || *checkpointOut != "" || checkpoint != nil {
+			log.Fatal("multi objective runs don't support islands or checkpoints")
+		}
+		ga := newGA(r)
+		nsga := &guillotine.NSGA2{
+			Spec:           spec,
+			Mutator:        ga.Mutator,
+			Breeder:        ga.Breeder,
+			R:              r,
+			PopulationSize: ga.PopulationSize,
+			Workers:        ga.Workers,
+			Stop:           guillotine.AnyOf(budget...),
+		}
+		names := strings.Split(*objectives, ",")
+		for _, name := range names {
+			objective, err := guillotine.ParseObjective(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			nsga.Objectives = append(nsga.Objectives, objective)
+		}
+		nsga.Observer = func(p *guillotine.Progress) {
+			if collector != nil {
+				collector.Observe(p)
+			}
+			if *progress {
+				fmt.Fprintf(os.Stderr, "generation %d: %d solutions on the front, %v\n",
+					p.Generation, len(nsga.Front(p.Population)), p.Elapsed)
+			}
+		}
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		result, err := nsga.RunContext(ctx)
+		if result == nil {
+			log.Fatal(err)
+		} else if err != nil {
+			log.Println("run interrupted:", err)
+		}
+		printFront(names, result, *ascii)
+		if *statsOut != "" {
+			writeStats()
+		}
+		return
+	}
+
+	var run func(ctx context.Context) (*guillotine.RunResult, error)
+	if *islands > 1 && (*checkpointOut != "" || checkpoint != nil) {
+		log.Fatal("checkpoints are not supported with islands")
+	}
+	if *engine != "ga" && *engine != "brkga" && *engine != "annealing" {
+		log.Fatalf("invalid engine %q", *engine)
+	}
+	if *crowding && *steadyState != "" {
+		log.Fatal("crowding and steady state replacement can't be combined")
+	}
+	if *engine == "brkga" && (*crowding || *steadyState != "") {
+		log.Fatal("brkga runs don't support crowding or steady state replacement")
+	}
+	if *engine == "brkga" {
+		if *brkgaElite <= 0 || *brkgaElite >= 1 {
+			log.Fatalf("brkgaElite must be in (0, 1), got %v", *brkgaElite)
+		}
+		if *brkgaMutants < 0 || *brkgaElite+*brkgaMutants >= 1 {
+			log.Fatalf("brkgaMutants must be positive and leave room for children after the elite, got %v", *brkgaMutants)
+		}
+		if *brkgaRho < 0.5 || *brkgaRho > 1 {
+			log.Fatalf("brkgaRho must be in [0.5, 1], got %v", *brkgaRho)
+		}
+	}
+	if *engine == "annealing" && (*islands > 1 || *checkpointOut != "" || checkpoint != nil) {
+		log.Fatal("annealing runs don't support islands or checkpoints")
+	}
+	if *engine == "annealing" {
+		schedule, err := guillotine.ParseCooling(*cooling, *coolingRate)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *moves < 1 {
+			log.Fatalf("moves must be at least 1, got %d", *moves)
+		}
+		if *restarts < 0 || *restarts >= *generations {
+			log.Fatalf("restarts must be between 0 and the generations, got %d", *restarts)
+		}
+		sa := &guillotine.SimulatedAnnealing{
+			Spec:        spec,
+			Evaluator:   evaluator,
+			Mutator:     newGA(r).Mutator,
+			Cooling:     schedule,
+			Temperature: *temperature,
+			Moves:       uint(*moves),
+			Levels:      uint(*generations / (*restarts + 1)),
+			Restarts:    uint(*restarts),
+			R:           r,
+			Source:      src,
+			Observer:    observer,
+			Stop:        guillotine.AnyOf(stop...),
+			Cache:       cache,
+		}
+		if *seedLayouts != "" {
+			names := strings.Split(*seedLayouts, ",")
+			if len(names) > 1 {
+				log.Printf("annealing starts from a single layout, ignoring all seeds but %s", names[0])
+			}
+			sa.Start = loadSeed(names[0], spec, r)
+		}
+		run = sa.RunContext
+	} else if *islands > 1 {
+		var topology guillotine.Topology
+		switch *topologyName {
+		case "ring":
+			topology = guillotine.RingTopology
+		case "full":
+			topology = guillotine.FullTopology
+		case "random":
+			topology = guillotine.RandomTopology
+		default:
+			log.Fatalf("invalid topology %q", *topologyName)
+		}
+		model := &guillotine.IslandModel{
+			Topology:          topology,
+			Selection:         guillotine.BestMigrants,
+			MigrationInterval: uint(*migrationInterval),
+			Migrants:          uint(*migrants),
+			R:                 r,
+			Stop:              guillotine.AnyOf(stop...),
+			Observer:          observer,
+		}
+		for i := 0; i < *islands; i++ {
+			model.Islands = append(model.Islands, newGA(rand.New(rand.NewSource(r.Int63()))))
+		}
+		//Seeds are dealt to the islands in turns
+		if *seedLayouts != "" {
+			for i, name := range strings.Split(*seedLayouts, ",") {
+				island := model.Islands[i%*islands]
+				island.Seeds = append(island.Seeds, loadSeed(name, spec, r))
+			}
+		}
+		run = model.RunContext
+	} else {
+		ga := newGA(r)
+		ga.Source = src
+		if *seedLayouts != "" {
+			for _, name := range strings.Split(*seedLayouts, ",") {
+				ga.Seeds = append(ga.Seeds, loadSeed(name, spec, r))
+			}
+		}
+		ga.Stop = guillotine.AnyOf(stop...)
+		ga.Observer = func(p *guillotine.Progress) {
+			observer(p)
+			if *checkpointOut != "" && p.Generation%uint(*checkpointEvery) == 0 {
+				cp, err := ga.Checkpoint(p)
+				if err != nil {
+					log.Fatal(err)
+				}
+				cp.Benchmark = benchmark
+				if collector != nil {
+					cp.Stats = collector.Generations
+				}
+				if err := cp.Save(*checkpointOut); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+		if checkpoint != nil {
+			run = func(ctx context.Context) (*guillotine.RunResult, error) {
+				return ga.ResumeContext(ctx, checkpoint)
+			}
+		} else {
+			run = ga.RunContext
+		}
+		if *adaptive && *progress {
+			defer func() {
+				for _, step := range ga.Adapter.(*guillotine.AdaptiveMutator).Trajectory {
+					fmt.Fprintf(os.Stderr, "generation %d: success %.2f, weight mean %.2f, config mean %.2f\n",
+						step.Generation, step.SuccessRate, step.WeightMean, step.ConfigMean)
+				}
+			}()
+		}
+	}
 
-	if *maxWidth == 0 {
-		width, height = guillotine.AreaDimensions(float64(*area), r)
-		limitWidth = false
+	//Interrupting the run still outputs the best layout found so far
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	result, err := run(ctx)
+	if err != nil {
+		log.Println("run interrupted:", err)
+	}
+	bestLayout := result.Layout
+	drawing := guillotine.NewDrawer(bestLayout).Draw()
+	b, err := json.Marshal(drawing)
+	if err != nil {
+		log.Fatal("error:", err)
+	}
+	os.Stdout.Write(b)
+	if *saveLayout != "" {
+		writeFile(*saveLayout, func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(Solution{spec, bestLayout})
+		})
+	}
+	if *ascii > 0 {
+		fmt.Print("\n", drawing.Text(*ascii))
+	}
+	if *pngOut != "" {
+		writeFile(*pngOut, func(w io.Writer) error {
+			return drawing.WritePNG(w, drawing.FitScale(*imageSize))
+		})
+	}
+	if *gifOut != "" {
+		writeFile(*gifOut, recorder.Encode)
+	}
+	if *statsOut != "" {
+		writeStats()
+	}
+	if *dxfOut != "" {
+		origin, err := guillotine.ParseCorner(*dxfOrigin)
+		if err != nil {
+			log.Fatal(err)
+		}
+		units, err := guillotine.ParseDXFUnits(*dxfUnits)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts := guillotine.DXFOptions{Origin: origin, Units: units}
+		if spec.MaxWidth != 0 {
+			opts.Sheet = guillotine.Board{Width: spec.MaxWidth, Height: drawing.Sheet.Height}
+		}
+		writeFile(*dxfOut, func(w io.Writer) error {
+			return guillotine.WriteDXF(w, bestLayout, opts)
+		})
+	}
+	best := result.Fitness
+	if benchmark != nil && benchmark.Knapsack() {
+		value := benchmark.TotalValue() - best
+		fmt.Printf("\n%s value: %d", benchmark.Name, value)
+		if benchmark.BestValue > 0 {
+			fmt.Printf(", published %d (%.2f%% gap)\n", benchmark.BestValue, 100*benchmark.ValueGap(value))
+		} else {
+			fmt.Println()
+		}
+	} else if benchmark != nil {
+		fmt.Printf("\n%s height: %d", benchmark.Name, best)
+		if benchmark.BestKnown > 0 {
+			fmt.Printf(", best known %d (%.2f%% gap)", benchmark.BestKnown, 100*benchmark.Gap(best))
+		}
+		fmt.Printf("\nWaste: %.2f%%\n", 100*(float32(best*spec.MaxWidth)/float32(target)-1))
 	} else {
-		width, height = guillotine.MaxWidthDimensions(*maxWidth, r)
-		limitWidth = true
-	}
-	spec := guillotine.NewRandomSpec(*nboards, width, height, r, limitWidth)
-	target := width * height
-
-	ga := &guillotine.GeneticAlgorithm{
-		Spec:        spec,
-		Evaluator:   (*guillotine.LayoutTree).Area,
-		Mutator: guillotine.CompoundWeightConfigMutator{
-			Weight: guillotine.NormalWeightMutator{
-				Mean:   *weightMutateMean,
-				StdDev: *weightMutateMean / 5,
-			},
-			Config: guillotine.NormalConfigMutator{
-				Mean:   *configMutateMean,
-				StdDev: *configMutateMean / 5,
-			},
-		}.Mutate,
-		Breeder:         crossover,
-		SelectorBuilder: guillotine.NewTournamentSelectorBuilder(*tsize, float32(*psel), r, true),
-		R:               r,
-		EliteSize:       uint(*eliteSize),
-	}
-	pop := guillotine.NewRandomPopulation(uint16(*nboards), uint(*population), r)
-	rankedPop := ga.Evaluate(pop)
-	for i := 1; i < *generations; i++ {
-		pop = ga.Next(rankedPop)
-		rankedPop = ga.Evaluate(pop)
-	}
-
-	bestLayout := guillotine.GetPhenotype(spec, rankedPop.Pop[0])
-	drawer := guillotine.NewDrawer(bestLayout)
-	b, err := json.Marshal(drawer.Draw())
+		fmt.Printf("\nWaste: %.2f%%\n", 100*(float32(best)/float32(target)-1))
+	}
+	fmt.Printf("Stopped after %d generations: %s\n", result.Generations, result.Reason)
+	if cache != nil {
+		stats := cache.Stats()
+		fmt.Printf("Cache: %d hits, %d misses (%.1f%%)\n", stats.Hits, stats.Misses, 100*stats.HitRate())
+	}
+	seedUsed, _ := src.State()
+	fmt.Printf("Seed: %d\n", seedUsed)
+}
+
+//A Pareto front solution, as printed by multi objective runs
+type ParetoLayout struct {
+	Objectives map[string]uint     `json:"objectives"`
+	Drawing    *guillotine.Drawing `json:"drawing"`
+}
+
+func printFront(names []string, result *guillotine.ParetoResult, ascii int) {
+	front := make([]ParetoLayout, len(result.Front))
+	for i, solution := range result.Front {
+		front[i].Objectives = make(map[string]uint)
+		for k, name := range names {
+			front[i].Objectives[name] = solution.Values[k]
+		}
+		front[i].Drawing = guillotine.NewDrawer(solution.Layout).Draw()
+	}
+	b, err := json.Marshal(front)
 	if err != nil {
 		log.Fatal("error:", err)
 	}
 	os.Stdout.Write(b)
-	best := rankedPop.Fitnesses[0]
-	fmt.Printf("\nWaste: %.2f%%\n", 100*(float32(best)/float32(target)-1))
+	if ascii > 0 {
+		for i, solution := range result.Front {
+			fmt.Printf("\n%v\n%s", solution.Values, front[i].Drawing.Text(ascii))
+		}
+	}
+	fmt.Printf("\nStopped after %d generations: %s\n", result.Generations, result.Reason)
+}
+
+//Genotype for a layout saved by a previous run on the same spec
+func loadSeed(name string, spec *guillotine.CutSpec, r *rand.Rand) guillotine.Genotype {
+	f, err := os.Open(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	var solution Solution
+	if err := json.NewDecoder(f).Decode(&solution); err != nil {
+		log.Fatal(err)
+	}
+	if !reflect.DeepEqual(solution.Spec.Boards, spec.Boards) {
+		log.Fatalf("%s: layout is for a different spec", name)
+	}
+	solution.Layout.Spec = spec
+	g, err := guillotine.EncodeLayout(solution.Layout, r)
+	if err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+	return g
+}
+
+//A problem from a benchmark file, with best known heights from an
+//optional file.
+func loadBenchmark(name, format string, problem int, bestKnown string) *guillotine.Benchmark {
+	benchmarks, err := guillotine.LoadBenchmarks(name, format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if problem < 1 || problem > len(benchmarks) {
+		log.Fatalf("%s has %d problems", name, len(benchmarks))
+	}
+	if bestKnown != "" {
+		f, err := os.Open(bestKnown)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err = guillotine.ReadBestKnown(f, benchmarks); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return benchmarks[problem-1]
+}
+
+//Sheet without waste for the spec, which frames rendered images
+func idealSheet(spec *guillotine.CutSpec) (width, height uint) {
+	if spec.MaxWidth != 0 {
+		return spec.MaxWidth, spec.TotalArea / spec.MaxWidth
+	}
+	side := uint(math.Sqrt(float64(spec.TotalArea)))
+	return side, side
+}
+
+func writeFile(name string, write func(w io.Writer) error) {
+	f, err := os.Create(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := write(f); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,105 @@
+package guillotine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rdarder/guillotine/evolve"
+)
+
+//Summary of a single generation.
+type GenerationStats struct {
+	Generation uint    `json:"generation"`
+	Best       uint    `json:"best"`
+	Worst      uint    `json:"worst"`
+	Mean       float64 `json:"mean"`
+	StdDev     float64 `json:"stddev"`
+	//Fraction of the population with distinct layouts, see DistinctLayouts
+	Diversity   float64       `json:"diversity"`
+	Elapsed     time.Duration `json:"elapsed"`
+	Evaluations uint          `json:"evaluations"`
+	BestSoFar   uint          `json:"bestSoFar"`
+}
+
+func NewGenerationStats(spec *CutSpec, p *Progress) GenerationStats {
+	rp := p.Population
+	summary := evolve.Summarize(rp)
+	return GenerationStats{
+		Generation:  p.Generation,
+		Best:        summary.Best,
+		Worst:       summary.Worst,
+		Mean:        summary.Mean,
+		StdDev:      summary.StdDev,
+		Diversity:   float64(len(DistinctLayouts(spec, rp).Pop)) / float64(len(rp.Pop)),
+		Elapsed:     p.Elapsed,
+		Evaluations: p.Evaluations,
+		BestSoFar:   p.BestSoFar,
+	}
+}
+
+//Records the stats of every generation of a run. Its Observe method
+//is meant to be the GeneticAlgorithm Observer, or one of them, see
+//Observers. Diversity decodes the whole population, which costs about
+//as much as evaluating it with a cheap Evaluator.
+type StatsCollector struct {
+	Spec        *CutSpec
+	Generations []GenerationStats
+}
+
+func NewStatsCollector(spec *CutSpec) *StatsCollector {
+	return &StatsCollector{Spec: spec}
+}
+
+func (sc *StatsCollector) Observe(p *Progress) {
+	sc.Generations = append(sc.Generations, NewGenerationStats(sc.Spec, p))
+}
+
+var statsHeader = []string{
+	"generation", "best", "worst", "mean", "stddev", "diversity", "elapsed", "evaluations", "bestSoFar",
+}
+
+//One line per generation after a header, elapsed time in seconds.
+func (sc *StatsCollector) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(statsHeader)
+	for _, gs := range sc.Generations {
+		cw.Write([]string{
+			strconv.FormatUint(uint64(gs.Generation), 10),
+			strconv.FormatUint(uint64(gs.Best), 10),
+			strconv.FormatUint(uint64(gs.Worst), 10),
+			strconv.FormatFloat(gs.Mean, 'f', -1, 64),
+			strconv.FormatFloat(gs.StdDev, 'f', -1, 64),
+			strconv.FormatFloat(gs.Diversity, 'f', -1, 64),
+			strconv.FormatFloat(gs.Elapsed.Seconds(), 'f', -1, 64),
+			strconv.FormatUint(uint64(gs.Evaluations), 10),
+			strconv.FormatUint(uint64(gs.BestSoFar), 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+//One JSON object per generation and line, elapsed time in nanoseconds.
+func (sc *StatsCollector) WriteJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, gs := range sc.Generations {
+		if err := enc.Encode(gs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Observer that notifies every given observer in turn, nils are skipped.
+func Observers(observers ...Observer) Observer {
+	return func(p *Progress) {
+		for _, o := range observers {
+			if o != nil {
+				o(p)
+			}
+		}
+	}
+}
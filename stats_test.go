@@ -0,0 +1,108 @@
+package guillotine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func collectedRun(t *testing.T) *StatsCollector {
+	ga := testGA(rand.New(rand.NewSource(1)), 8)
+	ga.Generations = 20
+	collector := NewStatsCollector(ga.Spec)
+	var calls int
+	ga.Observer = Observers(collector.Observe, nil, func(*Progress) { calls++ })
+	if _, err := ga.RunContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 20 {
+		t.Fatalf("Expected every observer to be notified 20 times, got %d", calls)
+	}
+	return collector
+}
+
+func TestStatsCollector(t *testing.T) {
+	collector := collectedRun(t)
+	if len(collector.Generations) != 20 {
+		t.Fatalf("Expected stats for 20 generations, got %d", len(collector.Generations))
+	}
+	for i, gs := range collector.Generations {
+		if gs.Generation != uint(i+1) || gs.Evaluations != uint(20*(i+1)) {
+			t.Errorf("Expected generation %d after %d evaluations, got %+v", i+1, 20*(i+1), gs)
+		}
+		if float64(gs.Best) > gs.Mean || gs.Mean > float64(gs.Worst) || gs.BestSoFar > gs.Best {
+			t.Errorf("Expected best <= mean <= worst, got %+v", gs)
+		}
+		if gs.Diversity <= 0 || gs.Diversity > 1 || gs.StdDev < 0 {
+			t.Errorf("Expected diversity in (0, 1], got %+v", gs)
+		}
+	}
+}
+
+func TestIslandStats(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	model := &IslandModel{
+		Topology:          RingTopology,
+		Selection:         BestMigrants,
+		MigrationInterval: 2,
+		Migrants:          1,
+		R:                 r,
+		Stop:              MaxGenerations(5),
+	}
+	for i := 0; i < 2; i++ {
+		island := testGA(rand.New(rand.NewSource(r.Int63())), 8)
+		if i > 0 {
+			island.Spec = model.Islands[0].Spec
+		}
+		model.Islands = append(model.Islands, island)
+	}
+	collector := NewStatsCollector(model.Islands[0].Spec)
+	model.Observer = collector.Observe
+	if _, err := model.RunContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(collector.Generations) != 5 {
+		t.Fatalf("Expected stats for 5 generations, got %d", len(collector.Generations))
+	}
+	for _, gs := range collector.Generations {
+		if float64(gs.Best) > gs.Mean || gs.Mean > float64(gs.Worst) || gs.Diversity <= 0 {
+			t.Errorf("Expected best <= mean <= worst over both islands, got %+v", gs)
+		}
+	}
+}
+
+func TestStatsExport(t *testing.T) {
+	collector := collectedRun(t)
+	var b bytes.Buffer
+	if err := collector.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 21 || records[0][0] != "generation" || records[20][0] != "20" {
+		t.Errorf("Expected a header and 20 generations, got %d records", len(records))
+	}
+	b.Reset()
+	if err := collector.WriteJSONL(&b); err != nil {
+		t.Fatal(err)
+	}
+	var lines int
+	for scanner := bufio.NewScanner(&b); scanner.Scan(); lines++ {
+		var gs GenerationStats
+		if err := json.Unmarshal(scanner.Bytes(), &gs); err != nil {
+			t.Fatal(err)
+		}
+		if gs != collector.Generations[lines] {
+			t.Errorf("Expected %+v, got %+v", collector.Generations[lines], gs)
+		}
+	}
+	if lines != 20 {
+		t.Errorf("Expected 20 lines, got %d", lines)
+	}
+}
@@ -0,0 +1,94 @@
+package guillotine
+
+//Steady state evolution: every child replaces an individual as soon as
+//it's evaluated, so the next parents can already be picked among the
+//children. A generation is as many children as the population size.
+//Elites are never replaced.
+type SteadyState struct {
+	//Size of the tournament picking the individual to replace, the
+	//least fit of it wins. 0 always replaces the worst individual.
+	Tournament int
+}
+
+//Index of the individual replaced by the next child, out of the n-keep
+//least fit ones.
+func (ss *SteadyState) victim(n, keep int, ga *GeneticAlgorithm) int {
+	if ss.Tournament <= 0 {
+		return n - 1
+	}
+	victim := keep
+	for k := 0; k < ss.Tournament; k++ {
+		if i := keep + ga.R.Intn(n-keep); i > victim {
+			victim = i
+		}
+	}
+	return victim
+}
+
+//Parents are drawn by a selector rebuilt before every pair of them, so
+//wheel based selectors and fitness Sharing account for the children
+//replaced so far. Niche counts for Sharing are computed once per
+//generation and then updated on every replacement. Evolves rp in
+//place, returning it along with the fraction of children fitter than
+//both their parents.
+func (ga *GeneticAlgorithm) steady(rp *RankedPopulation) (*RankedPopulation, float64) {
+	n, keep := len(rp.Pop), int(ga.EliteSize)
+	if keep >= n {
+		return rp, 0
+	}
+	e := ga.engine()
+	var niche []float64
+	selector := e.Select
+	if ga.Sharing != nil {
+		niche = ga.Sharing.niches(rp.Pop)
+		selector = func(rp *RankedPopulation) func() int {
+			view, index := sharedView(rp, niche)
+			selector := e.SelectorBuilder(view)
+			return func() int {
+				return index[selector.NextIndex()]
+			}
+		}
+	}
+	var successes int
+	for children := 0; children < n; children += 2 {
+		next := selector(rp)
+		p1, p2 := next(), next()
+		parent := rp.Fitnesses[p1]
+		if f := rp.Fitnesses[p2]; f < parent {
+			parent = f
+		}
+		c1, c2 := e.Breed(rp.Pop[p1], rp.Pop[p2])
+		pair := Population{c1}
+		//the second child of an odd population's last pair is left out
+		if children+1 < n {
+			pair = append(pair, c2)
+		}
+		for _, child := range pair {
+			fitness := ga.fitness(child)
+			if fitness < parent {
+				successes++
+			}
+			v := ga.SteadyState.victim(n, keep, ga)
+			var count float64
+			if niche != nil {
+				count = ga.Sharing.replace(rp.Pop, niche, v, child)
+			}
+			i := rp.Replace(v, child, fitness)
+			if niche != nil {
+				moveNiche(niche, v, i, count)
+			}
+		}
+	}
+	return rp, float64(successes) / float64(n)
+}
+
+//Moves niche counts like RankedPopulation.Replace moves individuals,
+//setting the one at i.
+func moveNiche(niche []float64, v, i int, count float64) {
+	if i > v {
+		copy(niche[v:i], niche[v+1:i+1])
+	} else {
+		copy(niche[i+1:v+1], niche[i:v])
+	}
+	niche[i] = count
+}
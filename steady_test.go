@@ -0,0 +1,97 @@
+package guillotine
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestRankedPopulationReplace(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	rp := &RankedPopulation{Pop: NewRandomPopulation(4, 6, r), Fitnesses: []uint{1, 3, 5, 7, 9, 11}}
+	g := NewRandomGenotype(4, r)
+	rp.Replace(5, g, 4)
+	if !sort.IsSorted(rp) || rp.Fitnesses[2] != 4 || rp.Pop[2][0] != g[0] {
+		t.Errorf("Expected the worst to be replaced in place, got %v", rp.Fitnesses)
+	}
+	rp.Replace(0, g, 8)
+	if !sort.IsSorted(rp) || rp.Fitnesses[0] != 3 || rp.Fitnesses[4] != 8 {
+		t.Errorf("Expected the best to be replaced in place, got %v", rp.Fitnesses)
+	}
+}
+
+func TestSteadyState(t *testing.T) {
+	for _, tournament := range []int{0, 3} {
+		ga := testGA(rand.New(rand.NewSource(1)), 8)
+		ga.Generations = 20
+		ga.SteadyState = &SteadyState{Tournament: tournament}
+		var previous uint
+		ga.Observer = func(p *Progress) {
+			if !sort.IsSorted(p.Population) || len(p.Population.Pop) != 20 {
+				t.Errorf("Expected a sorted population of 20, got %v", p.Population.Fitnesses)
+			}
+			if previous != 0 && p.Best > previous {
+				t.Errorf("Expected elites to be kept, got %d after %d", p.Best, previous)
+			}
+			previous = p.Best
+		}
+		result, err := ga.RunContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Evaluations != 20*20 {
+			t.Errorf("Expected a generation to be as many children as individuals, got %d evaluations",
+				result.Evaluations)
+		}
+		for i, g := range result.Population.Pop {
+			if f := GetPhenotype(ga.Spec, g).Area(); f != result.Population.Fitnesses[i] {
+				t.Errorf("Expected fitness %d at %d, got %d", f, i, result.Population.Fitnesses[i])
+			}
+		}
+	}
+}
+
+func TestSteadyStateSharing(t *testing.T) {
+	ga := testGA(rand.New(rand.NewSource(1)), 8)
+	ga.Generations = 10
+	ga.SteadyState = &SteadyState{Tournament: 2}
+	ga.Sharing = &FitnessSharing{Radius: 0.2, Alpha: 1}
+	ga.SelectorBuilder = NewRouletteSelectorBuilder(ga.R, true)
+	mutator := NewAdaptiveMutator(4, 4)
+	ga.Mutator, ga.Adapter = mutator.Mutate, mutator
+	result, err := ga.RunContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sort.IsSorted(result.Population) || len(result.Population.Pop) != 20 {
+		t.Errorf("Expected a sorted population of 20, got %v", result.Population.Fitnesses)
+	}
+	var improved bool
+	for _, step := range mutator.Trajectory {
+		if step.SuccessRate < 0 || step.SuccessRate > 1 {
+			t.Errorf("Unexpected success rate %v", step.SuccessRate)
+		}
+		improved = improved || step.SuccessRate > 0
+	}
+	if !improved {
+		t.Error("Expected some children to beat their parents")
+	}
+}
+
+func TestSteadyStateOddPopulation(t *testing.T) {
+	ga := testGA(rand.New(rand.NewSource(1)), 8)
+	ga.PopulationSize, ga.Generations = 7, 3
+	ga.SteadyState = &SteadyState{}
+	var evaluations int
+	ga.Evaluator = func(lt *LayoutTree) uint {
+		evaluations++
+		return lt.Area()
+	}
+	if _, err := ga.RunContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if evaluations != 3*7 {
+		t.Errorf("Expected 7 evaluations per generation, got %d in 3", evaluations)
+	}
+}
@@ -0,0 +1,55 @@
+package guillotine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rdarder/guillotine/evolve"
+)
+
+//Decides whether a run should end after the given progress, and
+//describes why.
+type StopCondition = evolve.StopCondition[Genotype, *LayoutTree, uint]
+
+func MaxGenerations(n uint) StopCondition {
+	return evolve.MaxGenerations[Genotype, *LayoutTree, uint](n)
+}
+
+//Stops when the best fitness didn't improve for n generations.
+func Stagnation(n uint) StopCondition {
+	return evolve.Stagnation[Genotype, *LayoutTree, uint](n)
+}
+
+//Stops once a fitness at least as good as target is found.
+func TargetFitness(target uint) StopCondition {
+	return evolve.TargetFitness[Genotype, *LayoutTree](target)
+}
+
+//Stops once a layout reaches a lower bound of the fitness, as no
+//better layout can be found. See CutSpec.AreaLowerBound and
+//CutSpec.HeightLowerBound.
+func LowerBound(bound uint) StopCondition {
+	return func(p *Progress) (bool, string) {
+		return p.BestSoFar <= bound, fmt.Sprintf("reached lower bound %d", bound)
+	}
+}
+
+func EvaluationBudget(n uint) StopCondition {
+	return evolve.EvaluationBudget[Genotype, *LayoutTree, uint](n)
+}
+
+//Stops when the next generation is expected to end past the limit,
+//extrapolating from the average generation time.
+func WallClock(limit time.Duration) StopCondition {
+	return evolve.WallClock[Genotype, *LayoutTree, uint](limit)
+}
+
+//Stops as soon as any of the conditions does, with its reason.
+func AnyOf(conditions ...StopCondition) StopCondition {
+	return evolve.AnyOf(conditions...)
+}
+
+//Stops when all the conditions do, with all their reasons.
+func AllOf(conditions ...StopCondition) StopCondition {
+	return evolve.AllOf(conditions...)
+}
@@ -0,0 +1,46 @@
+package guillotine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStopConditions(t *testing.T) {
+	p := &Progress{Generation: 10, Improved: 6, Evaluations: 500, BestSoFar: 40}
+	if stop, _ := Stagnation(5)(p); stop {
+		t.Error("Expected no stagnation after 4 generations")
+	}
+	if stop, reason := Stagnation(4)(p); !stop || reason != "no improvement for 4 generations" {
+		t.Errorf("Expected stagnation after 4 generations, got %v: %v", stop, reason)
+	}
+	first := AnyOf(MaxGenerations(20), EvaluationBudget(500))
+	if stop, reason := first(p); !stop || reason != "reached 500 evaluations" {
+		t.Errorf("Expected evaluation budget stop, got %v: %v", stop, reason)
+	}
+	all := AllOf(LowerBound(40), MaxGenerations(20))
+	if stop, _ := all(p); stop {
+		t.Error("Expected no stop until every condition holds")
+	}
+	p.Generation = 20
+	if stop, reason := all(p); !stop || reason != "reached lower bound 40 and reached 20 generations" {
+		t.Errorf("Expected combined stop, got %v: %v", stop, reason)
+	}
+}
+
+func TestRandomSpecArea(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, limitWidth := range []bool{false, true} {
+		spec := NewRandomSpec(12, 40, 50, r, limitWidth)
+		var area uint
+		for _, b := range spec.Boards {
+			area += b.Area()
+		}
+		if spec.TotalArea != 40*50 || area != spec.TotalArea {
+			t.Errorf("Expected the boards to cover the 40x50 sheet, got %d and total area %d", area, spec.TotalArea)
+		}
+		if spec.AreaLowerBound() != 40*50 || limitWidth && spec.HeightLowerBound() != 50 {
+			t.Errorf("Expected the sheet to be the lower bound, got area %d and height %d",
+				spec.AreaLowerBound(), spec.HeightLowerBound())
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package guillotine
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+//Box drawing connections of a character cell
+const (
+	linkUp = 1 << iota
+	linkDown
+	linkLeft
+	linkRight
+)
+
+var boxRunes = [16]rune{
+	' ', '│', '│', '│',
+	'─', '┘', '┐', '┤',
+	'─', '└', '┌', '├',
+	'─', '┴', '┬', '┼',
+}
+
+const (
+	textWaste = '░'
+	//Terminal cells are about twice as tall as they are wide
+	textAspect = 2
+)
+
+type textGrid struct {
+	links [][]uint8
+	runes [][]rune
+}
+
+func newTextGrid(cols, rows int) *textGrid {
+	g := &textGrid{links: make([][]uint8, rows), runes: make([][]rune, rows)}
+	for y := range g.links {
+		g.links[y] = make([]uint8, cols)
+		g.runes[y] = make([]rune, cols)
+		for x := range g.runes[y] {
+			g.runes[y][x] = textWaste
+		}
+	}
+	return g
+}
+
+func (g *textGrid) frame(x0, y0, x1, y1 int) {
+	for x := x0; x < x1; x++ {
+		g.links[y0][x] |= linkRight
+		g.links[y0][x+1] |= linkLeft
+		g.links[y1][x] |= linkRight
+		g.links[y1][x+1] |= linkLeft
+	}
+	for y := y0; y < y1; y++ {
+		g.links[y][x0] |= linkDown
+		g.links[y+1][x0] |= linkUp
+		g.links[y][x1] |= linkDown
+		g.links[y+1][x1] |= linkUp
+	}
+}
+
+func (g *textGrid) fill(x0, y0, x1, y1 int, r rune) {
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			g.runes[y][x] = r
+		}
+	}
+}
+
+//Writes label centered in the box interior, as long as it fits.
+func (g *textGrid) label(x0, y0, x1, y1 int, label string) {
+	if len(label) > x1-x0-1 || y1-y0 < 2 {
+		return
+	}
+	y := (y0 + y1) / 2
+	x := x0 + 1 + (x1-x0-1-len(label))/2
+	for i, r := range label {
+		g.runes[y][x+i] = r
+	}
+}
+
+func (g *textGrid) String() string {
+	var b strings.Builder
+	for y, row := range g.runes {
+		for x, r := range row {
+			if l := g.links[y][x]; l != 0 {
+				r = boxRunes[l]
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+//Renders the drawing as text, scaled to be cols characters wide.
+//Parts are framed with box drawing characters and labeled with their
+//index when there's room for it, waste is shaded.
+func (d *Drawing) Text(cols int) string {
+	if cols < 2 || d.Sheet.Width == 0 {
+		return ""
+	}
+	sx := float64(cols-1) / float64(d.Sheet.Width)
+	sy := sx / textAspect
+	col := func(v uint) int { return int(math.Floor(float64(v)*sx + 0.5)) }
+	row := func(v uint) int { return int(math.Floor(float64(v)*sy + 0.5)) }
+	g := newTextGrid(cols, row(d.Sheet.Height)+1)
+	for i, box := range d.Boxes {
+		x0, y0 := col(box.X), row(box.Y)
+		x1, y1 := col(box.X+box.Width), row(box.Y+box.Height)
+		g.fill(x0, y0, x1, y1, ' ')
+		g.frame(x0, y0, x1, y1)
+		g.label(x0, y0, x1, y1, strconv.Itoa(i))
+	}
+	g.frame(0, 0, col(d.Sheet.Width), row(d.Sheet.Height))
+	return g.String()
+}
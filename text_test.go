@@ -0,0 +1,29 @@
+package guillotine
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDrawingText(t *testing.T) {
+	drawing := NewDrawer(randomLayout(rand.New(rand.NewSource(1)), 8)).Draw()
+	text := drawing.Text(41)
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	rows := int(float64(drawing.Sheet.Height)*40/float64(drawing.Sheet.Width)/2+0.5) + 1
+	if len(lines) != rows {
+		t.Errorf("Expected %d rows, got %d:\n%s", rows, len(lines), text)
+	}
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n != 41 {
+			t.Fatalf("Expected rows 41 characters wide, got %d:\n%s", n, text)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "┌") || !strings.HasSuffix(lines[len(lines)-1], "┘") {
+		t.Errorf("Expected the sheet to be framed:\n%s", text)
+	}
+	if drawing.Text(1) != "" {
+		t.Errorf("Expected no text for less than 2 columns")
+	}
+}
@@ -0,0 +1,555 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/rdarder/guillotine"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* Search of genetic algorithm parameters over a set of benchmark specs */
+
+//Tuned parameters, named as in the endpoints GeneticAlgorithmParams so
+//the best configuration can be used as hints.
+type Params struct {
+	Population         uint
+	TournamentSize     uint
+	FittestProbability float32
+	EliteSize          uint
+	Crossover          string
+	WeightMutateMean   float64
+	ConfigMutateMean   float64
+}
+
+//Values to try for every parameter.
+type Space struct {
+	Population         []uint
+	TournamentSize     []uint
+	FittestProbability []float32
+	EliteSize          []uint
+	Crossover          []string
+	WeightMutateMean   []float64
+	ConfigMutateMean   []float64
+}
+
+func (s *Space) size() int {
+	return len(s.Population) * len(s.TournamentSize) * len(s.FittestProbability) *
+		len(s.EliteSize) * len(s.Crossover) * len(s.WeightMutateMean) * len(s.ConfigMutateMean)
+}
+
+//The i-th combination of values, in [0, size()).
+func (s *Space) at(i int) Params {
+	pick := func(n int) int {
+		k := i % n
+		i /= n
+		return k
+	}
+	return Params{
+		Population:         s.Population[pick(len(s.Population))],
+		TournamentSize:     s.TournamentSize[pick(len(s.TournamentSize))],
+		FittestProbability: s.FittestProbability[pick(len(s.FittestProbability))],
+		EliteSize:          s.EliteSize[pick(len(s.EliteSize))],
+		Crossover:          s.Crossover[pick(len(s.Crossover))],
+		WeightMutateMean:   s.WeightMutateMean[pick(len(s.WeightMutateMean))],
+		ConfigMutateMean:   s.ConfigMutateMean[pick(len(s.ConfigMutateMean))],
+	}
+}
+
+//Every combination for grid search, or n distinct random ones.
+func (s *Space) candidates(strategy string, n int, r *rand.Rand) []Params {
+	size := s.size()
+	var indices []int
+	if strategy == "grid" || n >= size {
+		indices = make([]int, size)
+		for i := range indices {
+			indices[i] = i
+		}
+	} else {
+		indices = r.Perm(size)[:n]
+	}
+	var params []Params
+	for _, i := range indices {
+		if p := s.at(i); p.valid() {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+func (p Params) valid() bool {
+	return p.Population > 1 && p.EliteSize < p.Population &&
+		p.TournamentSize >= 1 && p.TournamentSize <= p.Population &&
+		p.FittestProbability > 0 && p.FittestProbability <= 1
+}
+
+func (p Params) crossover() guillotine.Crossover {
+	switch p.Crossover {
+	case "uniform":
+		return guillotine.UniformCrossover
+	case "onepoint":
+		return guillotine.OnePointCrossover
+	case "twopoint":
+		return guillotine.TwoPointCrossover
+	case "blend":
+		return guillotine.BlendCrossover{Alpha: 0.5}.Crossover
+	case "arithmetic":
+		return guillotine.ArithmeticCrossover
+	case "sbx":
+		return guillotine.SBXCrossover{Eta: 2}.Crossover
+	case "order":
+		return guillotine.OrderCrossover
+	}
+	panic("Invalid option for crossover: " + p.Crossover)
+}
+
+//A benchmark spec and the seed of a run on it.
+type Instance struct {
+	Spec      *guillotine.CutSpec
+	Seed      int64
+	Evaluator guillotine.Fitness
+	//Relative distance from a fitness to the ideal one, the score
+	Gap func(fitness uint) float64
+	//Runs stop once they reach it
+	Bound uint
+}
+
+//Distance relative to a reference fitness.
+func relativeTo(reference uint) func(uint) float64 {
+	return func(fitness uint) float64 {
+		return float64(fitness)/float64(reference) - 1
+	}
+}
+
+//Random specs are scored by the area of their layouts.
+func randomInstance(spec *guillotine.CutSpec, seed int64) Instance {
+	return Instance{spec, seed, (*guillotine.LayoutTree).Area, relativeTo(spec.TotalArea), spec.AreaLowerBound()}
+}
+
+//Strip packing benchmarks are scored by height, relative to the best
+//known one when there's one. Knapsack ones by the value lost to the
+//published one, or to the total value when there's none.
+func benchmarkInstance(b *guillotine.Benchmark, seed int64) Instance {
+	in := Instance{b.Spec, seed, b.Fitness(), nil, b.Target()}
+	if b.Knapsack() {
+		total := b.TotalValue()
+		reference := b.BestValue
+		if reference == 0 || reference > total {
+			reference = total
+		}
+		in.Gap = func(fitness uint) float64 {
+			return 1 - float64(total-fitness)/float64(reference)
+		}
+		return in
+	}
+	if in.Bound < b.Spec.HeightLowerBound() {
+		in.Bound = b.Spec.HeightLowerBound()
+	}
+	reference := b.BestKnown
+	if reference == 0 {
+		reference = in.Bound
+	}
+	in.Gap = relativeTo(reference)
+	return in
+}
+
+//Fitness of the best layout found by a run with the given parameters,
+//relative to the instance reference, after the same amount of
+//evaluations for every configuration so larger populations don't get
+//more work done.
+func (p Params) score(in Instance, evaluations uint) float64 {
+	r := rand.New(rand.NewSource(in.Seed))
+	ga := &guillotine.GeneticAlgorithm{
+		Spec:      in.Spec,
+		Evaluator: in.Evaluator,
+		Mutator: guillotine.CompoundWeightConfigMutator{
+			Weight: guillotine.NormalWeightMutator{Mean: p.WeightMutateMean, StdDev: p.WeightMutateMean / 5},
+			Config: guillotine.NormalConfigMutator{Mean: p.ConfigMutateMean, StdDev: p.ConfigMutateMean / 5},
+		}.Mutate,
+		Breeder:         p.crossover(),
+		SelectorBuilder: guillotine.NewTournamentSelectorBuilder(int(p.TournamentSize), p.FittestProbability, r, true),
+		R:               r,
+		EliteSize:       p.EliteSize,
+		PopulationSize:  p.Population,
+		Stop: guillotine.AnyOf(
+			guillotine.EvaluationBudget(evaluations),
+			guillotine.LowerBound(in.Bound),
+		),
+	}
+	result, _ := ga.RunContext(context.Background())
+	return in.Gap(result.Fitness)
+}
+
+type Candidate struct {
+	Params Params
+	//Score on each instance run so far, in instance order
+	Scores []float64
+	//Whether racing left it behind
+	Dropped bool
+}
+
+func (c *Candidate) mean() float64 {
+	m, _ := meanStdDev(c.Scores)
+	return m
+}
+
+//Scores every candidate on the instance, in parallel.
+func evaluate(candidates []*Candidate, in Instance, evaluations uint, workers int) {
+	jobs := make(chan *Candidate)
+	var wg sync.WaitGroup
+	scores := make(map[*Candidate]float64, len(candidates))
+	var mu sync.Mutex
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				s := c.Params.score(in, evaluations)
+				mu.Lock()
+				scores[c] = s
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, c := range candidates {
+		jobs <- c
+	}
+	close(jobs)
+	wg.Wait()
+	for _, c := range candidates {
+		c.Scores = append(c.Scores, scores[c])
+	}
+}
+
+//Races candidates across instances, dropping the ones that are worse
+//than the current best with the given confidence, by a paired t-test
+//over the instances run so far.
+func race(candidates []*Candidate, instances []Instance, evaluations uint, workers int,
+	minInstances int, confidence float64, verbose bool) []*Candidate {
+
+	alive := candidates
+	for k, in := range instances {
+		evaluate(alive, in, evaluations, workers)
+		if k+1 < minInstances || len(alive) == 1 {
+			continue
+		}
+		best := bestCandidate(alive)
+		survivors := alive[:0:0]
+		for _, c := range alive {
+			if c == best || pairedConfidence(c.Scores, best.Scores) < confidence {
+				survivors = append(survivors, c)
+			} else {
+				c.Dropped = true
+			}
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "instance %d: %d of %d candidates left\n", k+1, len(survivors), len(alive))
+		}
+		alive = survivors
+		if len(alive) == 1 {
+			break
+		}
+	}
+	return alive
+}
+
+func bestCandidate(candidates []*Candidate) *Candidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.mean() < best.mean() {
+			best = c
+		}
+	}
+	return best
+}
+
+func meanStdDev(xs []float64) (mean, stddev float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	for _, x := range xs {
+		stddev += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(xs)-1))
+}
+
+//Confidence that the scores in a are higher (worse) than the paired
+//ones in b, from a one sided paired t-test.
+func pairedConfidence(a, b []float64) float64 {
+	diffs := make([]float64, len(a))
+	for i := range a {
+		diffs[i] = a[i] - b[i]
+	}
+	mean, stddev := meanStdDev(diffs)
+	if stddev == 0 {
+		if mean > 0 {
+			return 1
+		}
+		return 0
+	}
+	n := float64(len(diffs))
+	return studentCDF(mean/(stddev/math.Sqrt(n)), n-1)
+}
+
+//Half width of the confidence interval for the mean of xs.
+func interval(xs []float64, confidence float64) float64 {
+	_, stddev := meanStdDev(xs)
+	if len(xs) < 2 {
+		return math.Inf(1)
+	}
+	n := float64(len(xs))
+	return studentQuantile(1-(1-confidence)/2, n-1) * stddev / math.Sqrt(n)
+}
+
+//Cumulative distribution of Student's t with df degrees of freedom.
+func studentCDF(t, df float64) float64 {
+	tail := 0.5 * betaIncomplete(df/2, 0.5, df/(df+t*t))
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+func studentQuantile(p, df float64) float64 {
+	lo, hi := -1e3, 1e3
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if studentCDF(mid, df) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+//Regularized incomplete beta function I_x(a, b).
+func betaIncomplete(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	} else if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+//Continued fraction for betaIncomplete, by the modified Lentz method.
+func betaFraction(a, b, x float64) float64 {
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	f := d
+	for m := 1.0; m < 300; m++ {
+		for _, num := range []float64{
+			m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m)),
+			-(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			f *= d * c
+		}
+		if math.Abs(d*c-1) < 1e-12 {
+			break
+		}
+	}
+	return f
+}
+
+func loadBenchmarks(names, format, bestKnown string) []*guillotine.Benchmark {
+	var benchmarks []*guillotine.Benchmark
+	for _, name := range strings.Split(names, ",") {
+		loaded, err := guillotine.LoadBenchmarks(name, format)
+		if err != nil {
+			log.Fatal(err)
+		}
+		benchmarks = append(benchmarks, loaded...)
+	}
+	if bestKnown != "" {
+		f, err := os.Open(bestKnown)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err = guillotine.ReadBestKnown(f, benchmarks); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return benchmarks
+}
+
+func uints(s string) []uint {
+	var values []uint
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			log.Fatal(err)
+		}
+		values = append(values, uint(v))
+	}
+	return values
+}
+
+func floats(s string) []float64 {
+	var values []float64
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func main() {
+	var strategy = flag.String("strategy", "racing", "Search strategy: random, grid or racing")
+	var samples = flag.Int("samples", 50, "Number of random configurations, for random search and racing")
+	var nspecs = flag.Int("specs", 5, "Number of random benchmark specs")
+	var benchmarkFiles = flag.String("benchmark", "", "comma separated benchmark files to tune on instead of random specs")
+	var benchmarkFormat = flag.String("format", "strip", "Benchmark files format: gcut, ngcut, cgcut or strip")
+	var bestKnownFile = flag.String("bestKnown", "", "file with lines of benchmark name and best known height, for strip files without one")
+	var nboards = flag.Int("nboards", 10, "Number of boards of each benchmark spec")
+	var area = flag.Int("area", 2000, "Target total area of each benchmark spec")
+	var runs = flag.Int("runs", 4, "Number of seeds run on each benchmark spec")
+	var evaluations = flag.Uint("evaluations", 20000, "Evaluations per run")
+	var minInstances = flag.Int("minInstances", 5, "Runs before racing starts dropping configurations")
+	var confidence = flag.Float64("confidence", 0.95, "Confidence level for dropping configurations and intervals")
+	var top = flag.Int("top", 10, "Number of configurations to report")
+	var workers = flag.Int("workers", runtime.NumCPU(), "Number of parallel runs")
+	var seed = flag.Int64("seed", time.Now().Unix(), "Random seed for repeatable tuning")
+	var verbose = flag.Bool("v", false, "report racing progress")
+	var population = flag.String("population", "50,100,200", "comma separated population sizes")
+	var tsize = flag.String("tsize", "3,5,8", "comma separated tournament sizes")
+	var psel = flag.String("psel", "0.7,0.9", "comma separated tournament fittest probabilities")
+	var eliteSize = flag.String("eliteSize", "2,5", "comma separated elite sizes")
+	var crossovers = flag.String("crossover", "uniform,twopoint,order", "comma separated crossovers")
+	var weightMutateMean = flag.String("weightMutateMean", "2,5,10", "comma separated weight mutation means")
+	var configMutateMean = flag.String("configMutateMean", "2,5,10", "comma separated config mutation means")
+	flag.Parse()
+
+	space := &Space{
+		Population:       uints(*population),
+		TournamentSize:   uints(*tsize),
+		EliteSize:        uints(*eliteSize),
+		Crossover:        strings.Split(*crossovers, ","),
+		WeightMutateMean: floats(*weightMutateMean),
+		ConfigMutateMean: floats(*configMutateMean),
+	}
+	for _, p := range floats(*psel) {
+		space.FittestProbability = append(space.FittestProbability, float32(p))
+	}
+	for _, c := range space.Crossover {
+		(Params{Crossover: c}).crossover()
+	}
+
+	r := rand.New(rand.NewSource(*seed))
+	var instances []Instance
+	if *benchmarkFiles != "" {
+		for _, b := range loadBenchmarks(*benchmarkFiles, *benchmarkFormat, *bestKnownFile) {
+			for k := 0; k < *runs; k++ {
+				instances = append(instances, benchmarkInstance(b, r.Int63()))
+			}
+		}
+	} else {
+		for i := 0; i < *nspecs; i++ {
+			width, height := guillotine.AreaDimensions(float64(*area), r)
+			spec := guillotine.NewRandomSpec(*nboards, width, height, r, false)
+			for k := 0; k < *runs; k++ {
+				instances = append(instances, randomInstance(spec, r.Int63()))
+			}
+		}
+	}
+	//racing drops candidates early, mixing specs avoids judging on one
+	r.Shuffle(len(instances), func(i, j int) { instances[i], instances[j] = instances[j], instances[i] })
+
+	var params []Params
+	switch *strategy {
+	case "grid":
+		params = space.candidates("grid", 0, r)
+	case "random", "racing":
+		params = space.candidates("random", *samples, r)
+	default:
+		log.Fatalf("unknown strategy %q, expected random, grid or racing", *strategy)
+	}
+	if len(params) == 0 {
+		log.Fatal("no valid configurations in the search space")
+	}
+	candidates := make([]*Candidate, len(params))
+	for i, p := range params {
+		candidates[i] = &Candidate{Params: p}
+	}
+	fmt.Fprintf(os.Stderr, "%d configurations, %d instances\n", len(candidates), len(instances))
+
+	if *strategy == "racing" {
+		alive := race(candidates, instances, *evaluations, *workers, *minInstances, *confidence, *verbose)
+		fmt.Printf("%d configurations survived the race\n", len(alive))
+	} else {
+		for k, in := range instances {
+			evaluate(candidates, in, *evaluations, *workers)
+			if *verbose {
+				fmt.Fprintf(os.Stderr, "instance %d of %d\n", k+1, len(instances))
+			}
+		}
+	}
+
+	//rank the configurations that survived the race first, then the
+	//ones that lasted the longest
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.Dropped != cj.Dropped {
+			return !ci.Dropped
+		}
+		if len(ci.Scores) != len(cj.Scores) {
+			return len(ci.Scores) > len(cj.Scores)
+		}
+		return ci.mean() < cj.mean()
+	})
+	if *top > len(candidates) {
+		*top = len(candidates)
+	}
+	//relative to the best known fitness or a lower bound
+	fmt.Printf("%4s %10s %10s %5s  %s\n", "rank", "gap", "interval", "runs", "params")
+	for i, c := range candidates[:*top] {
+		b, _ := json.Marshal(c.Params)
+		fmt.Printf("%4d %9.2f%% %9.2f%% %5d  %s\n",
+			i+1, 100*c.mean(), 100*interval(c.Scores, *confidence), len(c.Scores), b)
+	}
+	best := candidates[0]
+	b, _ := json.MarshalIndent(best.Params, "", "  ")
+	fmt.Printf("\nBest configuration:\n%s\n", b)
+	if len(candidates) > 1 && len(candidates[1].Scores) == len(best.Scores) {
+		fmt.Printf("Better than the runner-up with %.1f%% confidence (paired t-test over %d runs)\n",
+			100*pairedConfidence(candidates[1].Scores, best.Scores), len(best.Scores))
+	}
+}
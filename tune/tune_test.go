@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/rdarder/guillotine"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestBetaIncomplete(t *testing.T) {
+	cases := []struct{ a, b, x, expected float64 }{
+		{1, 1, 0.3, 0.3},
+		{3, 1, 0.5, 0.125},
+		{4, 4, 0.5, 0.5},
+		{2, 3, 0.4, 0.5248},
+		{2, 3, 0, 0},
+		{2, 3, 1, 1},
+	}
+	for _, c := range cases {
+		if i := betaIncomplete(c.a, c.b, c.x); !near(i, c.expected, 1e-9) {
+			t.Errorf("Expected I_%v(%v, %v) = %v, got %v", c.x, c.a, c.b, c.expected, i)
+		}
+	}
+}
+
+func TestStudent(t *testing.T) {
+	cases := []struct{ t, df, p float64 }{
+		{0, 4, 0.5},
+		//Cauchy distribution
+		{1, 1, 0.75},
+		{2.228139, 10, 0.975},
+		{2.015048, 5, 0.95},
+		{-2.015048, 5, 0.05},
+	}
+	for _, c := range cases {
+		if p := studentCDF(c.t, c.df); !near(p, c.p, 1e-6) {
+			t.Errorf("Expected P(T < %v) = %v with %v degrees of freedom, got %v", c.t, c.p, c.df, p)
+		}
+		if c.t != 0 {
+			if q := studentQuantile(c.p, c.df); !near(q, c.t, 1e-5) {
+				t.Errorf("Expected quantile %v of %v to be %v with %v degrees of freedom, got %v",
+					c.p, c.df, c.t, c.df, q)
+			}
+		}
+	}
+}
+
+func TestPairedConfidence(t *testing.T) {
+	b := []float64{0.1, 0.2, 0.3}
+	if c := pairedConfidence([]float64{1.1, 1.2, 1.3}, b); c != 1 {
+		t.Errorf("Expected certainty for uniformly worse scores, got %v", c)
+	}
+	if c := pairedConfidence(b, []float64{1.1, 1.2, 1.3}); c != 0 {
+		t.Errorf("Expected no confidence for uniformly better scores, got %v", c)
+	}
+	//differences 1, 2 and 3 make t = 2*sqrt(3) with 2 degrees of freedom
+	if c := pairedConfidence([]float64{1.1, 2.2, 3.3}, b); !near(c, 0.962910, 1e-6) {
+		t.Errorf("Expected a confidence of 0.962910, got %v", c)
+	}
+}
+
+func TestRace(t *testing.T) {
+	good := Params{Population: 40, TournamentSize: 3, FittestProbability: 0.8, EliteSize: 2,
+		Crossover: "uniform", WeightMutateMean: 2, ConfigMutateMean: 2}
+	//two individuals that barely change
+	dominated := Params{Population: 2, TournamentSize: 1, FittestProbability: 1, EliteSize: 1,
+		Crossover: "uniform"}
+	r := rand.New(rand.NewSource(1))
+	var instances []Instance
+	for i := 0; i < 8; i++ {
+		width, height := guillotine.AreaDimensions(2000, r)
+		spec := guillotine.NewRandomSpec(12, width, height, r, false)
+		instances = append(instances, randomInstance(spec, r.Int63()))
+	}
+	candidates := []*Candidate{{Params: dominated}, {Params: good}}
+	alive := race(candidates, instances, 2000, 2, 3, 0.95, false)
+	if len(alive) != 1 || alive[0].Params != good || !candidates[0].Dropped {
+		t.Errorf("Expected the dominated candidate to be dropped, got %d survivors", len(alive))
+	}
+	if n := len(candidates[0].Scores); n < 3 || n == len(instances) {
+		t.Errorf("Expected the dominated candidate to be dropped early, after %d instances", n)
+	}
+}